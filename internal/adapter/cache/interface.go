@@ -12,15 +12,37 @@ type TicketCache interface {
 	InvalidateTicket(ctx context.Context, ticketID string) error
 
 	// User tickets caching
-	CacheUserTickets(ctx context.Context, userID string, tickets []*models.Ticket) error
-	GetUserTickets(ctx context.Context, userID string) ([]*models.Ticket, error)
+	// CacheUserTickets keeps the first page of a user's tickets in default order.
+	CacheUserTickets(ctx context.Context, userID string, page *models.TicketPage) error
+	GetUserTickets(ctx context.Context, userID string) (*models.TicketPage, error)
 	InvalidateUserTickets(ctx context.Context, userID string) error
 
-	// Seat availability caching
-	CacheSeatAvailability(ctx context.Context, sessionID, seatNumber string, available bool) error
-	GetSeatAvailability(ctx context.Context, sessionID, seatNumber string) (*bool, error)
+	// Session tickets caching
+	CacheSessionTickets(ctx context.Context, sessionID string, tickets []*models.Ticket) error
+	GetSessionTickets(ctx context.Context, sessionID string) ([]*models.Ticket, error)
+	InvalidateSessionTickets(ctx context.Context, sessionID string) error
+
+	// Session seat map caching
+	CacheSeatMap(ctx context.Context, seatMap *models.SeatMap) error
+	GetSeatMap(ctx context.Context, sessionID string) (*models.SeatMap, error)
+	CacheSeatState(ctx context.Context, sessionID, seatNumber string, state models.SeatState) error
+	GetSeatState(ctx context.Context, sessionID, seatNumber string) (*models.SeatState, error)
+	InvalidateSeatMap(ctx context.Context, sessionID string) error
+
+	// Report caching
+	CacheSalesReport(ctx context.Context, report *models.SalesReport) error
+	GetSalesReport(ctx context.Context, query models.SalesReportQuery) (*models.SalesReport, error)
 
 	// Health check
 	Ping(ctx context.Context) error
 	Close() error
 }
+
+// SeatLocker serializes competing reservations of the same seat across
+// service replicas. AcquireSeatLock returns an owner token that must be
+// handed back to ReleaseSeatLock, so a holder whose lock already expired
+// cannot release a lock that now belongs to someone else.
+type SeatLocker interface {
+	AcquireSeatLock(ctx context.Context, sessionID, seatNumber string) (string, error)
+	ReleaseSeatLock(ctx context.Context, sessionID, seatNumber, token string) error
+}
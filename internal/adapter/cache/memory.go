@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+)
+
+type seatLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+// MemorySeatLocker is an in-process SeatLocker for tests and single-instance runs.
+type MemorySeatLocker struct {
+	mu    sync.Mutex
+	locks map[string]seatLock
+	ttl   time.Duration
+}
+
+func NewMemorySeatLocker(ttl time.Duration) *MemorySeatLocker {
+	return &MemorySeatLocker{
+		locks: make(map[string]seatLock),
+		ttl:   ttl,
+	}
+}
+
+func (m *MemorySeatLocker) AcquireSeatLock(ctx context.Context, sessionID, seatNumber string) (string, error) {
+	key := m.key(sessionID, seatNumber)
+
+	token, err := newLockToken()
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if lock, ok := m.locks[key]; ok && now.Before(lock.expiresAt) {
+		return "", models.ErrSeatLocked
+	}
+
+	m.locks[key] = seatLock{token: token, expiresAt: now.Add(m.ttl)}
+
+	return token, nil
+}
+
+func (m *MemorySeatLocker) ReleaseSeatLock(ctx context.Context, sessionID, seatNumber, token string) error {
+	key := m.key(sessionID, seatNumber)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lock, ok := m.locks[key]; ok && lock.token == token {
+		delete(m.locks, key)
+	}
+
+	return nil
+}
+
+func (m *MemorySeatLocker) key(sessionID, seatNumber string) string {
+	return fmt.Sprintf("%s:%s", sessionID, seatNumber)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+)
+
+func TestMemorySeatLocker(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemorySeatLocker(time.Minute)
+
+	token, err := locker.AcquireSeatLock(ctx, "s1", "A1")
+	if err != nil {
+		t.Fatalf("AcquireSeatLock() error = %v", err)
+	}
+
+	if _, err := locker.AcquireSeatLock(ctx, "s1", "A1"); !errors.Is(err, models.ErrSeatLocked) {
+		t.Fatalf("second AcquireSeatLock() error = %v, want %v", err, models.ErrSeatLocked)
+	}
+
+	// Other seats and sessions are locked independently.
+	if _, err := locker.AcquireSeatLock(ctx, "s1", "A2"); err != nil {
+		t.Fatalf("AcquireSeatLock(A2) error = %v", err)
+	}
+	if _, err := locker.AcquireSeatLock(ctx, "s2", "A1"); err != nil {
+		t.Fatalf("AcquireSeatLock(s2) error = %v", err)
+	}
+
+	// A stale token does not release someone else's lock.
+	if err := locker.ReleaseSeatLock(ctx, "s1", "A1", "not-the-owner"); err != nil {
+		t.Fatalf("ReleaseSeatLock() error = %v", err)
+	}
+	if _, err := locker.AcquireSeatLock(ctx, "s1", "A1"); !errors.Is(err, models.ErrSeatLocked) {
+		t.Fatalf("AcquireSeatLock() after foreign release error = %v, want %v", err, models.ErrSeatLocked)
+	}
+
+	if err := locker.ReleaseSeatLock(ctx, "s1", "A1", token); err != nil {
+		t.Fatalf("ReleaseSeatLock() error = %v", err)
+	}
+	if _, err := locker.AcquireSeatLock(ctx, "s1", "A1"); err != nil {
+		t.Fatalf("AcquireSeatLock() after release error = %v", err)
+	}
+}
+
+func TestMemorySeatLockerExpiry(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemorySeatLocker(10 * time.Millisecond)
+
+	if _, err := locker.AcquireSeatLock(ctx, "s1", "A1"); err != nil {
+		t.Fatalf("AcquireSeatLock() error = %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := locker.AcquireSeatLock(ctx, "s1", "A1"); err != nil {
+		t.Fatalf("AcquireSeatLock() after expiry error = %v", err)
+	}
+}
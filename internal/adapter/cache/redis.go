@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -11,9 +13,26 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	seatMapTTL = 10 * time.Minute // Shorter TTL for seat availability
+
+	// seatMapCompleteField marks a seat map hash loaded from the database in full.
+	seatMapCompleteField = "_complete"
+)
+
+// releaseLockScript deletes the lock only if it is still held by the caller's token.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
 type RedisCache struct {
-	client *redis.Client
-	ttl    time.Duration
+	client    *redis.Client
+	ttl       time.Duration
+	lockTTL   time.Duration
+	reportTTL time.Duration
 }
 
 func NewRedisCache(cfg config.Redis) *RedisCache {
@@ -24,8 +43,10 @@ func NewRedisCache(cfg config.Redis) *RedisCache {
 	})
 
 	return &RedisCache{
-		client: rdb,
-		ttl:    cfg.TTL,
+		client:    rdb,
+		ttl:       cfg.TTL,
+		lockTTL:   cfg.LockTTL,
+		reportTTL: cfg.ReportTTL,
 	}
 }
 
@@ -67,16 +88,18 @@ func (r *RedisCache) GetTicket(ctx context.Context, ticketID string) (*models.Ti
 	var ticket models.Ticket
 	err = json.Unmarshal([]byte(data), &ticket)
 	if err != nil {
+		// Entries written before prices became Money no longer decode; drop them as a miss.
+		_ = r.client.Del(ctx, key).Err()
 		return nil, fmt.Errorf("failed to unmarshal ticket: %w", err)
 	}
 
 	return &ticket, nil
 }
 
-func (r *RedisCache) CacheUserTickets(ctx context.Context, userID string, tickets []*models.Ticket) error {
+func (r *RedisCache) CacheUserTickets(ctx context.Context, userID string, page *models.TicketPage) error {
 	key := r.userTicketsKey(userID)
 
-	data, err := json.Marshal(tickets)
+	data, err := json.Marshal(page)
 	if err != nil {
 		return fmt.Errorf("failed to marshal user tickets: %w", err)
 	}
@@ -89,7 +112,7 @@ func (r *RedisCache) CacheUserTickets(ctx context.Context, userID string, ticket
 	return nil
 }
 
-func (r *RedisCache) GetUserTickets(ctx context.Context, userID string) ([]*models.Ticket, error) {
+func (r *RedisCache) GetUserTickets(ctx context.Context, userID string) (*models.TicketPage, error) {
 	key := r.userTicketsKey(userID)
 
 	data, err := r.client.Get(ctx, key).Result()
@@ -100,13 +123,14 @@ func (r *RedisCache) GetUserTickets(ctx context.Context, userID string) ([]*mode
 		return nil, fmt.Errorf("failed to get user tickets from cache: %w", err)
 	}
 
-	var tickets []*models.Ticket
-	err = json.Unmarshal([]byte(data), &tickets)
+	var page models.TicketPage
+	err = json.Unmarshal([]byte(data), &page)
 	if err != nil {
+		_ = r.client.Del(ctx, key).Err()
 		return nil, fmt.Errorf("failed to unmarshal user tickets: %w", err)
 	}
 
-	return tickets, nil
+	return &page, nil
 }
 
 func (r *RedisCache) InvalidateTicket(ctx context.Context, ticketID string) error {
@@ -119,30 +143,204 @@ func (r *RedisCache) InvalidateUserTickets(ctx context.Context, userID string) e
 	return r.client.Del(ctx, key).Err()
 }
 
-func (r *RedisCache) CacheSeatAvailability(ctx context.Context, sessionID, seatNumber string, available bool) error {
-	key := r.seatKey(sessionID, seatNumber)
+func (r *RedisCache) CacheSessionTickets(ctx context.Context, sessionID string, tickets []*models.Ticket) error {
+	key := r.sessionTicketsKey(sessionID)
 
-	err := r.client.Set(ctx, key, available, 10*time.Minute).Err() // Shorter TTL for seat availability
+	data, err := json.Marshal(tickets)
 	if err != nil {
-		return fmt.Errorf("failed to cache seat availability: %w", err)
+		return fmt.Errorf("failed to marshal session tickets: %w", err)
+	}
+
+	err = r.client.Set(ctx, key, data, r.ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to cache session tickets: %w", err)
 	}
 
 	return nil
 }
 
-func (r *RedisCache) GetSeatAvailability(ctx context.Context, sessionID, seatNumber string) (*bool, error) {
-	key := r.seatKey(sessionID, seatNumber)
+func (r *RedisCache) GetSessionTickets(ctx context.Context, sessionID string) ([]*models.Ticket, error) {
+	key := r.sessionTicketsKey(sessionID)
 
-	result, err := r.client.Get(ctx, key).Result()
+	data, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, nil // Cache miss
 		}
-		return nil, fmt.Errorf("failed to get seat availability from cache: %w", err)
+		return nil, fmt.Errorf("failed to get session tickets from cache: %w", err)
+	}
+
+	var tickets []*models.Ticket
+	err = json.Unmarshal([]byte(data), &tickets)
+	if err != nil {
+		_ = r.client.Del(ctx, key).Err()
+		return nil, fmt.Errorf("failed to unmarshal session tickets: %w", err)
+	}
+
+	return tickets, nil
+}
+
+func (r *RedisCache) InvalidateSessionTickets(ctx context.Context, sessionID string) error {
+	key := r.sessionTicketsKey(sessionID)
+	return r.client.Del(ctx, key).Err()
+}
+
+func (r *RedisCache) CacheSeatMap(ctx context.Context, seatMap *models.SeatMap) error {
+	key := r.seatMapKey(seatMap.SessionID)
+
+	fields := make(map[string]interface{}, len(seatMap.Seats)+1)
+	for seat, state := range seatMap.Seats {
+		fields[seat] = string(state)
+	}
+	fields[seatMapCompleteField] = "1"
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, fields)
+		pipe.Expire(ctx, key, seatMapTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cache seat map: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RedisCache) GetSeatMap(ctx context.Context, sessionID string) (*models.SeatMap, error) {
+	key := r.seatMapKey(sessionID)
+
+	fields, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat map from cache: %w", err)
+	}
+
+	// Only individual seats were written since the last full load.
+	if _, ok := fields[seatMapCompleteField]; !ok {
+		return nil, nil // Cache miss
+	}
+	delete(fields, seatMapCompleteField)
+
+	seatMap := &models.SeatMap{
+		SessionID: sessionID,
+		Seats:     make(map[string]models.SeatState, len(fields)),
+	}
+	for seat, state := range fields {
+		if models.SeatState(state) == models.SeatStateFree {
+			continue
+		}
+		seatMap.Seats[seat] = models.SeatState(state)
+	}
+
+	return seatMap, nil
+}
+
+func (r *RedisCache) CacheSeatState(ctx context.Context, sessionID, seatNumber string, state models.SeatState) error {
+	key := r.seatMapKey(sessionID)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, seatNumber, string(state))
+		pipe.Expire(ctx, key, seatMapTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cache seat state: %w", err)
 	}
 
-	available := result == "true"
-	return &available, nil
+	return nil
+}
+
+func (r *RedisCache) GetSeatState(ctx context.Context, sessionID, seatNumber string) (*models.SeatState, error) {
+	key := r.seatMapKey(sessionID)
+
+	values, err := r.client.HMGet(ctx, key, seatNumber, seatMapCompleteField).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat state from cache: %w", err)
+	}
+
+	if state, ok := values[0].(string); ok {
+		seatState := models.SeatState(state)
+		return &seatState, nil
+	}
+
+	// A fully loaded map omits free seats.
+	if values[1] != nil {
+		seatState := models.SeatStateFree
+		return &seatState, nil
+	}
+
+	return nil, nil // Cache miss
+}
+
+func (r *RedisCache) InvalidateSeatMap(ctx context.Context, sessionID string) error {
+	key := r.seatMapKey(sessionID)
+	return r.client.Del(ctx, key).Err()
+}
+
+// CacheSalesReport keeps a report briefly. Reports are never invalidated,
+// so reportTTL bounds how stale a repeated request may be.
+func (r *RedisCache) CacheSalesReport(ctx context.Context, report *models.SalesReport) error {
+	key := r.salesReportKey(report.SalesReportQuery)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sales report: %w", err)
+	}
+
+	err = r.client.Set(ctx, key, data, r.reportTTL).Err()
+	if err != nil {
+		return fmt.Errorf("failed to cache sales report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RedisCache) GetSalesReport(ctx context.Context, query models.SalesReportQuery) (*models.SalesReport, error) {
+	key := r.salesReportKey(query)
+
+	data, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil // Cache miss
+		}
+		return nil, fmt.Errorf("failed to get sales report from cache: %w", err)
+	}
+
+	var report models.SalesReport
+	err = json.Unmarshal([]byte(data), &report)
+	if err != nil {
+		_ = r.client.Del(ctx, key).Err()
+		return nil, fmt.Errorf("failed to unmarshal sales report: %w", err)
+	}
+
+	return &report, nil
+}
+
+func (r *RedisCache) AcquireSeatLock(ctx context.Context, sessionID, seatNumber string) (string, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := r.client.SetNX(ctx, r.seatLockKey(sessionID, seatNumber), token, r.lockTTL).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire seat lock: %w", err)
+	}
+
+	if !ok {
+		return "", models.ErrSeatLocked
+	}
+
+	return token, nil
+}
+
+func (r *RedisCache) ReleaseSeatLock(ctx context.Context, sessionID, seatNumber, token string) error {
+	err := releaseLockScript.Run(ctx, r.client, []string{r.seatLockKey(sessionID, seatNumber)}, token).Err()
+	if err != nil {
+		return fmt.Errorf("failed to release seat lock: %w", err)
+	}
+
+	return nil
 }
 
 func (r *RedisCache) ticketKey(ticketID string) string {
@@ -153,6 +351,27 @@ func (r *RedisCache) userTicketsKey(userID string) string {
 	return fmt.Sprintf("user_tickets:%s", userID)
 }
 
-func (r *RedisCache) seatKey(sessionID, seatNumber string) string {
-	return fmt.Sprintf("seat:%s:%s", sessionID, seatNumber)
+func (r *RedisCache) sessionTicketsKey(sessionID string) string {
+	return fmt.Sprintf("session_tickets:%s", sessionID)
+}
+
+func (r *RedisCache) seatMapKey(sessionID string) string {
+	return fmt.Sprintf("session_seats:%s", sessionID)
+}
+
+func (r *RedisCache) salesReportKey(query models.SalesReportQuery) string {
+	return fmt.Sprintf("sales_report:%s:%d:%d", query.GroupBy, query.From.UnixMilli(), query.To.UnixMilli())
+}
+
+func (r *RedisCache) seatLockKey(sessionID, seatNumber string) string {
+	return fmt.Sprintf("seat_lock:%s:%s", sessionID, seatNumber)
+}
+
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
 }
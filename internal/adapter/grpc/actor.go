@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// authMetadataKey carries "Bearer <token>". The token is signed by the
+// auth service; its user_id claim names the actor behind the request and
+// its role claim their role. Requests without a token act anonymously.
+const authMetadataKey = "authorization"
+
+const bearerPrefix = "Bearer "
+
+func actorUnaryInterceptor(verifier TokenVerifier) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, err := withActor(ctx, verifier)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func actorStreamInterceptor(verifier TokenVerifier) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := withActor(ss.Context(), verifier)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &actorServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// actorServerStream overrides the stream context so handlers see the actor.
+type actorServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *actorServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// withActor attaches the actor and role proven by the request's bearer
+// token to ctx. A token that fails verification rejects the request rather
+// than letting it through anonymously.
+func withActor(ctx context.Context, verifier TokenVerifier) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	values := md.Get(authMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+
+	token, ok := strings.CutPrefix(values[0], bearerPrefix)
+	if !ok || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+
+	if verifier == nil {
+		return nil, status.Error(codes.Unauthenticated, "token authentication is not configured")
+	}
+
+	claims, err := verifier.VerifyAndParseClaims(token)
+	if err != nil || claims.UserID == nil || *claims.UserID == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+
+	ctx = models.WithActor(ctx, *claims.UserID)
+
+	if claims.Role != nil && *claims.Role != "" {
+		ctx = models.WithActorRole(ctx, *claims.Role)
+	}
+
+	return ctx, nil
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"github.com/sorawaslocked/ap2final_base/pkg/security"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestWithActor(t *testing.T) {
+	issuer := security.NewJWTProvider("test-secret", time.Minute, time.Minute)
+	forger := security.NewJWTProvider("other-secret", time.Minute, time.Minute)
+
+	adminToken, err := issuer.GenerateAccessToken("user-1", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	userToken, err := issuer.GenerateAccessToken("user-2", "user")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	forgedToken, err := forger.GenerateAccessToken("user-3", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		verifier  TokenVerifier
+		md        metadata.MD
+		wantActor string
+		wantAdmin bool
+		wantCode  codes.Code
+	}{
+		{
+			name:      "no token is anonymous",
+			verifier:  issuer,
+			md:        metadata.Pairs(),
+			wantActor: models.ActorSystem,
+		},
+		{
+			name:      "admin token",
+			verifier:  issuer,
+			md:        metadata.Pairs(authMetadataKey, bearerPrefix+adminToken),
+			wantActor: "user-1",
+			wantAdmin: true,
+		},
+		{
+			name:      "user token",
+			verifier:  issuer,
+			md:        metadata.Pairs(authMetadataKey, bearerPrefix+userToken),
+			wantActor: "user-2",
+		},
+		{
+			name:      "role claimed in plain metadata is ignored",
+			verifier:  issuer,
+			md:        metadata.Pairs("x-actor-id", "user-9", "x-actor-role", models.RoleAdmin),
+			wantActor: models.ActorSystem,
+		},
+		{
+			name:     "token signed with another key",
+			verifier: issuer,
+			md:       metadata.Pairs(authMetadataKey, bearerPrefix+forgedToken),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "not a bearer token",
+			verifier: issuer,
+			md:       metadata.Pairs(authMetadataKey, adminToken),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "token without a configured verifier",
+			md:       metadata.Pairs(authMetadataKey, bearerPrefix+adminToken),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := withActor(metadata.NewIncomingContext(context.Background(), tt.md), tt.verifier)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("withActor() code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := models.ActorFromContext(ctx); got != tt.wantActor {
+				t.Errorf("actor = %q, want %q", got, tt.wantActor)
+			}
+			if got := models.IsAdmin(ctx); got != tt.wantAdmin {
+				t.Errorf("IsAdmin() = %v, want %v", got, tt.wantAdmin)
+			}
+		})
+	}
+}
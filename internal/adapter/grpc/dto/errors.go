@@ -3,22 +3,175 @@ package dto
 import (
 	"ap2final_ticket_service/internal/models"
 	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 func FromError(err error) error {
+	var seatErr *models.SeatReservationError
+	if errors.As(err, &seatErr) {
+		return fromSeatReservationError(seatErr)
+	}
+
 	if errors.Is(err, models.ErrTicketNotFound) {
 		return status.Error(codes.NotFound, "ticket not found")
 	}
 
+	if errors.Is(err, models.ErrPaymentNotFound) {
+		return status.Error(codes.NotFound, "payment not found")
+	}
+
+	if errors.Is(err, models.ErrHallNotFound) {
+		return status.Error(codes.NotFound, "hall layout not found")
+	}
+
+	if errors.Is(err, models.ErrTransferNotFound) {
+		return status.Error(codes.NotFound, "ticket transfer not found")
+	}
+
+	if errors.Is(err, models.ErrTransferAlreadyPending) {
+		return status.Error(codes.AlreadyExists, "ticket already has a pending transfer")
+	}
+
+	if errors.Is(err, models.ErrTransferNotPending) ||
+		errors.Is(err, models.ErrShowtimeStarted) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if errors.Is(err, models.ErrUnauthenticated) {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	if errors.Is(err, models.ErrUserNotAllowed) {
+		return status.Error(codes.PermissionDenied, "user is not allowed to perform this action")
+	}
+
+	if errors.Is(err, models.ErrInvalidUserID) {
+		return status.Error(codes.InvalidArgument, "invalid user ID")
+	}
+
+	if errors.Is(err, models.ErrPromoCodeNotFound) {
+		return status.Error(codes.NotFound, "promo code not found")
+	}
+
+	if errors.Is(err, models.ErrPromoCodeAlreadyExists) {
+		return status.Error(codes.AlreadyExists, "promo code already exists")
+	}
+
+	if errors.Is(err, models.ErrInvalidPromoCode) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, models.ErrPromoCodeNotActive) ||
+		errors.Is(err, models.ErrPromoCodeNotApplicable) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if errors.Is(err, models.ErrPromoCodeExhausted) ||
+		errors.Is(err, models.ErrPromoCodeUserLimit) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
 	//if errors.Is(err, models.ErrTicketAlreadyExists) {
 	//	return status.Error(codes.AlreadyExists, "ticket already exists")
 	//}
 
+	if errors.Is(err, models.ErrSeatAlreadyTaken) {
+		return status.Error(codes.AlreadyExists, "seat already taken")
+	}
+
+	if errors.Is(err, models.ErrInvalidSeatNumber) {
+		return status.Error(codes.InvalidArgument, "invalid seat number")
+	}
+
+	if errors.Is(err, models.ErrSessionFull) {
+		return status.Error(codes.ResourceExhausted, "movie session is full")
+	}
+
+	if errors.Is(err, models.ErrTicketVersionConflict) {
+		return status.Error(codes.Aborted, "ticket was modified concurrently, try again")
+	}
+
+	if errors.Is(err, models.ErrSeatLocked) {
+		return status.Error(codes.Aborted, "seat is being reserved, try again")
+	}
+
+	if errors.Is(err, models.ErrTicketExpired) {
+		return status.Error(codes.FailedPrecondition, "ticket reservation has expired")
+	}
+
+	if errors.Is(err, models.ErrTicketNotReserved) ||
+		errors.Is(err, models.ErrTicketNotPaid) ||
+		errors.Is(err, models.ErrTicketAlreadyPaid) ||
+		errors.Is(err, models.ErrTicketCancelled) ||
+		errors.Is(err, models.ErrTicketRefunded) ||
+		errors.Is(err, models.ErrTicketUsed) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if errors.Is(err, models.ErrInvalidTicketStatus) {
+		return status.Error(codes.InvalidArgument, "invalid ticket status")
+	}
+
+	if errors.Is(err, models.ErrInsufficientFunds) {
+		return status.Error(codes.FailedPrecondition, "insufficient funds for payment")
+	}
+
+	if errors.Is(err, models.ErrPriceMismatch) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if errors.Is(err, models.ErrCurrencyMismatch) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, models.ErrInvalidPaymentMethod) {
+		return status.Error(codes.InvalidArgument, "invalid payment method")
+	}
+
+	if errors.Is(err, models.ErrPaymentInProgress) {
+		return status.Error(codes.Aborted, "payment for this ticket is already in progress")
+	}
+
+	if errors.Is(err, models.ErrPaymentFailed) {
+		return status.Error(codes.Aborted, "payment processing failed")
+	}
+
+	if errors.Is(err, models.ErrRefundFailed) {
+		return status.Error(codes.Aborted, "refund processing failed")
+	}
+
+	if errors.Is(err, models.ErrInvalidReportRequest) ||
+		errors.Is(err, models.ErrInvalidTicketFilter) ||
+		errors.Is(err, models.ErrInvalidPageRequest) ||
+		errors.Is(err, models.ErrInvalidCursor) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	if errors.Is(err, models.ErrInvalidTicketData) {
 		return status.Error(codes.InvalidArgument, "invalid input")
 	}
 
 	return status.Error(codes.Internal, "internal server error")
 }
+
+// fromSeatReservationError attaches one field violation per rejected seat.
+func fromSeatReservationError(err *models.SeatReservationError) error {
+	st := status.New(codes.FailedPrecondition, "some seats could not be reserved")
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(err.Seats))
+	for seat, seatErr := range err.Seats {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       seat,
+			Description: seatErr.Error(),
+		})
+	}
+
+	detailed, detailsErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func FromTicketEventToPb(event models.TicketEvent) *svc.TicketEvent {
+	return &svc.TicketEvent{
+		ID:        event.ID,
+		TicketID:  event.TicketID,
+		Actor:     event.Actor,
+		Reason:    event.Reason,
+		OldStatus: string(event.OldStatus),
+		NewStatus: string(event.NewStatus),
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}
+}
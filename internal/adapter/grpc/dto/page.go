@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"github.com/sorawaslocked/ap2final_protos_gen/base"
+)
+
+func ToPageRequest(pageSize int32, pageToken, sortBy string, descending bool) models.PageRequest {
+	return models.PageRequest{
+		Size:       int(pageSize),
+		Cursor:     pageToken,
+		SortBy:     models.TicketSortField(sortBy),
+		Descending: descending,
+	}
+}
+
+func FromTicketPageToPb(page models.TicketPage) []*base.Ticket {
+	ticketsPb := make([]*base.Ticket, len(page.Tickets))
+	for i, ticket := range page.Tickets {
+		ticketsPb[i] = FromTicketToPb(*ticket)
+	}
+
+	return ticketsPb
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func FromPaymentToPb(payment models.Payment) *svc.Payment {
+	return &svc.Payment{
+		ID:             payment.ID,
+		TicketIDs:      payment.TicketIDs,
+		Amount:         payment.Amount.Float(),
+		RefundedAmount: payment.RefundedAmount.Float(),
+		Currency:       payment.Amount.Currency,
+		Method:         payment.Method,
+		ProviderRef:    payment.ProviderRef,
+		Status:         string(payment.Status),
+		FailureReason:  payment.FailureReason,
+		CreatedAt:      timestamppb.New(payment.CreatedAt),
+		UpdatedAt:      timestamppb.New(payment.UpdatedAt),
+	}
+}
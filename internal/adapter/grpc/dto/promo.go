@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func ToPromoCodeFromCreateRequest(req *svc.CreatePromoCodeRequest, currency string) models.PromoCode {
+	return models.PromoCode{
+		Code:           req.Code,
+		DiscountType:   models.DiscountType(req.DiscountType),
+		PercentOff:     req.PercentOff,
+		AmountOff:      models.MoneyFromFloat(req.AmountOff, currency),
+		ValidFrom:      toTime(req.ValidFrom),
+		ValidUntil:     toTime(req.ValidUntil),
+		MaxRedemptions: req.MaxRedemptions,
+		MaxPerUser:     req.MaxPerUser,
+		MovieIDs:       req.MovieIDs,
+		SessionIDs:     req.ShowtimeIDs,
+	}
+}
+
+func FromPromoCodeToPb(promo models.PromoCode) *svc.PromoCode {
+	return &svc.PromoCode{
+		ID:             promo.ID,
+		Code:           promo.Code,
+		DiscountType:   string(promo.DiscountType),
+		PercentOff:     promo.PercentOff,
+		AmountOff:      promo.AmountOff.Float(),
+		ValidFrom:      fromTime(promo.ValidFrom),
+		ValidUntil:     fromTime(promo.ValidUntil),
+		MaxRedemptions: promo.MaxRedemptions,
+		MaxPerUser:     promo.MaxPerUser,
+		Redemptions:    promo.Redemptions,
+		MovieIDs:       promo.MovieIDs,
+		ShowtimeIDs:    promo.SessionIDs,
+		CreatedAt:      timestamppb.New(promo.CreatedAt),
+	}
+}
+
+// toTime and fromTime map an unset timestamp to the zero time and back.
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
+
+func fromTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func FromOccupancyReportToPb(report models.OccupancyReport) *svc.GetOccupancyReportResponse {
+	series := make([]*svc.OccupancyPoint, len(report.Series))
+	for i, point := range report.Series {
+		series[i] = fromOccupancyPointToPb(point)
+	}
+
+	return &svc.GetOccupancyReportResponse{
+		ShowtimeID:  report.SessionID,
+		Capacity:    int64(report.Capacity),
+		Interval:    string(report.Interval),
+		Current:     fromOccupancyPointToPb(report.Current),
+		Series:      series,
+		GeneratedAt: timestamppb.New(report.GeneratedAt),
+	}
+}
+
+func fromOccupancyPointToPb(point models.OccupancyPoint) *svc.OccupancyPoint {
+	return &svc.OccupancyPoint{
+		At:          fromTime(point.At),
+		Sold:        int64(point.Sold),
+		Held:        int64(point.Held),
+		Free:        int64(point.Free),
+		FillPercent: point.FillPercent,
+	}
+}
+
+func ToSalesReportParamsFromRequest(req *svc.GetSalesReportRequest) (time.Time, time.Time, models.ReportGroupBy) {
+	return toTime(req.From), toTime(req.To), models.ReportGroupBy(req.GroupBy)
+}
+
+func FromSalesReportToPb(report models.SalesReport) *svc.GetSalesReportResponse {
+	rows := make([]*svc.SalesReportRow, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = &svc.SalesReportRow{
+			Key:            row.Key,
+			Reserved:       row.Reserved,
+			Paid:           row.Paid,
+			Revenue:        row.Revenue.Float(),
+			Refunded:       row.Refunded.Float(),
+			NetRevenue:     row.NetRevenue.Float(),
+			Currency:       row.Revenue.Currency,
+			ConversionRate: row.ConversionRate,
+		}
+	}
+
+	return &svc.GetSalesReportResponse{
+		From:        timestamppb.New(report.From),
+		To:          timestamppb.New(report.To),
+		GroupBy:     string(report.GroupBy),
+		Rows:        rows,
+		GeneratedAt: timestamppb.New(report.GeneratedAt),
+	}
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// ToTicketFilterFromSearchRequest leaves every field the client did not
+// set out of the filter.
+func ToTicketFilterFromSearchRequest(req *svc.SearchRequest, currency string) models.TicketFilter {
+	filter := models.TicketFilter{
+		SessionID:     optionalString(req.ShowtimeID),
+		MovieID:       optionalString(req.MovieID),
+		UserID:        optionalString(req.UserID),
+		SeatPrefix:    optionalString(req.SeatPrefix),
+		PaymentMethod: optionalString(req.PaymentMethod),
+		CreatedFrom:   optionalTime(req.CreatedFrom),
+		CreatedTo:     optionalTime(req.CreatedTo),
+		PurchasedFrom: optionalTime(req.PurchasedFrom),
+		PurchasedTo:   optionalTime(req.PurchasedTo),
+		Statuses:      ToTicketStatuses(req.Statuses),
+	}
+
+	if req.MinPrice != nil {
+		filter.MinPrice = models.MoneyFromFloat(*req.MinPrice, currency).Ptr()
+	}
+
+	if req.MaxPrice != nil {
+		filter.MaxPrice = models.MoneyFromFloat(*req.MaxPrice, currency).Ptr()
+	}
+
+	return filter
+}
+
+func ToTicketStatuses(statuses []string) []models.TicketStatus {
+	var result []models.TicketStatus
+	for _, status := range statuses {
+		result = append(result, models.TicketStatus(status))
+	}
+
+	return result
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+
+	return &t
+}
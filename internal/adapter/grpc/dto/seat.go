@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
+	"sort"
+)
+
+func FromSeatMapToPb(seatMap models.SeatMap) []*svc.Seat {
+	seatNumbers := make([]string, 0, len(seatMap.Seats))
+	for seatNumber := range seatMap.Seats {
+		seatNumbers = append(seatNumbers, seatNumber)
+	}
+	sort.Strings(seatNumbers)
+
+	seats := make([]*svc.Seat, len(seatNumbers))
+	for i, seatNumber := range seatNumbers {
+		seats[i] = &svc.Seat{
+			SeatNumber: seatNumber,
+			Status:     string(seatMap.Seats[seatNumber]),
+		}
+	}
+
+	return seats
+}
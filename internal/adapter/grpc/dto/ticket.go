@@ -7,19 +7,30 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func ToTicketFromCreateRequest(req *svc.CreateRequest) models.Ticket {
+// Prices cross the API as major-unit floats in the service currency.
+func ToTicketFromCreateRequest(req *svc.CreateRequest, currency string) models.Ticket {
 	return models.Ticket{
 		UserID:        req.UserID,
 		MovieID:       req.MovieID,
 		SessionID:     req.ShowtimeID,
 		SeatNumber:    req.SeatNumber,
-		Price:         req.Price,
+		Price:         models.MoneyFromFloat(req.Price, currency),
 		Status:        models.TicketStatus(req.Status),
 		PaymentMethod: "",
 	}
 }
 
-func ToTicketUpdateFromUpdateRequest(req *svc.UpdateRequest) (string, models.TicketUpdateData) {
+// ToClientPrice reads the price a client expects to pay. Zero means the
+// client sent none and accepts the quoted price.
+func ToClientPrice(price float64, currency string) *models.Money {
+	if price == 0 {
+		return nil
+	}
+
+	return models.MoneyFromFloat(price, currency).Ptr()
+}
+
+func ToTicketUpdateFromUpdateRequest(req *svc.UpdateRequest, currency string) (string, models.TicketUpdateData) {
 	updateData := models.TicketUpdateData{}
 
 	if req.Status != nil {
@@ -28,7 +39,11 @@ func ToTicketUpdateFromUpdateRequest(req *svc.UpdateRequest) (string, models.Tic
 	}
 
 	if req.Price != nil {
-		updateData.Price = req.Price
+		updateData.Price = models.MoneyFromFloat(*req.Price, currency).Ptr()
+	}
+
+	if req.PaymentMethod != nil {
+		updateData.PaymentMethod = req.PaymentMethod
 	}
 
 	return req.ID, updateData
@@ -41,7 +56,7 @@ func FromTicketToPb(ticket models.Ticket) *base.Ticket {
 		MovieID:    ticket.MovieID,
 		ShowtimeID: ticket.SessionID,
 		SeatNumber: ticket.SeatNumber,
-		Price:      ticket.Price,
+		Price:      ticket.Price.Float(),
 		Status:     string(ticket.Status),
 		CreatedAt:  timestamppb.New(ticket.CreatedAt),
 		UpdatedAt:  timestamppb.New(ticket.UpdatedAt),
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"testing"
+)
+
+func TestToClientPrice(t *testing.T) {
+	tests := []struct {
+		name  string
+		price float64
+		want  *models.Money
+	}{
+		{name: "zero accepts the quote", price: 0},
+		{name: "major units to minor units", price: 2500, want: models.NewMoney(250000, "KZT").Ptr()},
+		{name: "fractions round to the nearest minor unit", price: 19.999, want: models.NewMoney(2000, "KZT").Ptr()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToClientPrice(tt.price, "KZT")
+
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("ToClientPrice() = %v, want nil", *got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("ToClientPrice() = %v, want %v", got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"ap2final_ticket_service/internal/models"
+	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func FromTicketTransferToPb(transfer models.TicketTransfer) *svc.TicketTransfer {
+	return &svc.TicketTransfer{
+		ID:         transfer.ID,
+		TicketID:   transfer.TicketID,
+		FromUserID: transfer.FromUserID,
+		ToUserID:   transfer.ToUserID,
+		Status:     string(transfer.Status),
+		CreatedAt:  timestamppb.New(transfer.CreatedAt),
+		UpdatedAt:  timestamppb.New(transfer.UpdatedAt),
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func (s *TicketServer) logError(op string, err error) {
-	if !errors.Is(err, models.ErrTicketNotFound) {
+	if !errors.Is(err, models.ErrTicketNotFound) && !errors.Is(err, models.ErrPaymentNotFound) {
 		s.log.Error(fmt.Sprintf("ticket %s", op), logger.Err(err))
 	}
 }
@@ -3,15 +3,38 @@ package grpc
 import (
 	"ap2final_ticket_service/internal/models"
 	"context"
+	"github.com/sorawaslocked/ap2final_base/pkg/security"
+	"time"
 )
 
+// TokenVerifier checks the signature of a bearer token and returns its claims.
+type TokenVerifier interface {
+	VerifyAndParseClaims(token string) (security.Claims, error)
+}
+
 type TicketUseCase interface {
-	ReserveTicket(ctx context.Context, sessionID, movieID, userID, seatNumber string, price float64) (*models.Ticket, error)
+	ReserveTicket(ctx context.Context, sessionID, movieID, userID, seatNumber string, price *models.Money, promoCode string) (*models.Ticket, error)
+	ReserveTickets(ctx context.Context, sessionID, movieID, userID string, seats []string, price *models.Money, promoCode string) ([]*models.Ticket, error)
 	ConfirmPayment(ctx context.Context, ticketID, paymentMethod string) (*models.Ticket, error)
 	CancelTicket(ctx context.Context, ticketID string) error
+	UpdateTicket(ctx context.Context, ticketID string, update models.TicketUpdateData) (*models.Ticket, error)
+	ExchangeTicket(ctx context.Context, ticketID, newSessionID, newSeatNumber string) (*models.Ticket, error)
+	InitiateTransfer(ctx context.Context, ticketID, toUserID string) (*models.TicketTransfer, error)
+	AcceptTransfer(ctx context.Context, transferID string) (*models.Ticket, error)
 	GetTicket(ctx context.Context, id string) (*models.Ticket, error)
-	GetUserTickets(ctx context.Context, userID string) ([]*models.Ticket, error)
-	GetAllTickets(ctx context.Context) ([]*models.Ticket, error)
-	GetMovieTickets(ctx context.Context, movieID string) ([]*models.Ticket, error)
+	GetUserTickets(ctx context.Context, userID string, page models.PageRequest) (*models.TicketPage, error)
+	GetAllTickets(ctx context.Context, page models.PageRequest) (*models.TicketPage, error)
+	GetMovieTickets(ctx context.Context, movieID string, page models.PageRequest) (*models.TicketPage, error)
+	GetSessionTickets(ctx context.Context, sessionID string, statuses []models.TicketStatus) ([]*models.Ticket, error)
+	SearchTickets(ctx context.Context, filter models.TicketFilter, page models.PageRequest) (*models.TicketPage, error)
+	StreamTickets(ctx context.Context, filter models.TicketFilter, page models.PageRequest, send func(*models.Ticket) error) error
 	CheckSeatAvailability(ctx context.Context, sessionID, seatNumber string) (bool, error)
+	GetSessionSeatMap(ctx context.Context, sessionID string) (*models.SeatMap, error)
+	QuotePrice(ctx context.Context, sessionID, movieID, seatNumber, promoCode string) (models.Money, error)
+	GetTicketHistory(ctx context.Context, ticketID string) ([]*models.TicketEvent, error)
+	GetPayment(ctx context.Context, id string) (*models.Payment, error)
+	GetTicketPayments(ctx context.Context, ticketID string) ([]*models.Payment, error)
+	CreatePromoCode(ctx context.Context, promo models.PromoCode) (*models.PromoCode, error)
+	GetSalesReport(ctx context.Context, from, to time.Time, groupBy models.ReportGroupBy) (*models.SalesReport, error)
+	GetOccupancyReport(ctx context.Context, sessionID string, interval models.OccupancyInterval) (*models.OccupancyReport, error)
 }
@@ -16,18 +16,24 @@ type Server struct {
 	addr          string
 	log           *slog.Logger
 	ticketUseCase TicketUseCase
+	verifier      TokenVerifier
+	currency      string
 }
 
 func New(
 	cfg grpccfg.Config,
 	log *slog.Logger,
 	ticketUseCase TicketUseCase,
+	verifier TokenVerifier,
+	currency string,
 ) *Server {
 	server := &Server{
 		cfg:           cfg,
 		addr:          fmt.Sprintf(":%d", cfg.Port),
 		log:           log,
 		ticketUseCase: ticketUseCase,
+		verifier:      verifier,
+		currency:      currency,
 	}
 
 	server.register()
@@ -50,9 +56,12 @@ func (s *Server) Stop() {
 }
 
 func (s *Server) register() {
-	s.s = grpc.NewServer()
+	s.s = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(actorUnaryInterceptor(s.verifier)),
+		grpc.ChainStreamInterceptor(actorStreamInterceptor(s.verifier)),
+	)
 
-	svc.RegisterTicketServiceServer(s.s, NewTicketServer(s.ticketUseCase, s.log))
+	svc.RegisterTicketServiceServer(s.s, NewTicketServer(s.ticketUseCase, s.currency, s.log))
 
 	reflection.Register(s.s)
 }
@@ -2,27 +2,30 @@ package grpc
 
 import (
 	"ap2final_ticket_service/internal/adapter/grpc/dto"
+	"ap2final_ticket_service/internal/models"
 	"context"
 	"github.com/sorawaslocked/ap2final_protos_gen/base"
 	svc "github.com/sorawaslocked/ap2final_protos_gen/service/ticket"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log/slog"
 )
 
 type TicketServer struct {
-	uc  TicketUseCase
-	log *slog.Logger
+	uc       TicketUseCase
+	currency string
+	log      *slog.Logger
 	svc.UnimplementedTicketServiceServer
 }
 
 func NewTicketServer(
 	uc TicketUseCase,
+	currency string,
 	log *slog.Logger,
 ) *TicketServer {
 	return &TicketServer{
-		uc:  uc,
-		log: log,
+		uc:       uc,
+		currency: currency,
+		log:      log,
 	}
 }
 
@@ -33,7 +36,8 @@ func (s *TicketServer) Create(ctx context.Context, req *svc.CreateRequest) (*svc
 		req.MovieID,
 		req.UserID,
 		req.SeatNumber,
-		req.Price,
+		dto.ToClientPrice(req.Price, s.currency),
+		req.PromoCode,
 	)
 	if err != nil {
 		s.logError("create", err)
@@ -45,6 +49,31 @@ func (s *TicketServer) Create(ctx context.Context, req *svc.CreateRequest) (*svc
 	}, nil
 }
 
+func (s *TicketServer) CreateMany(ctx context.Context, req *svc.CreateManyRequest) (*svc.CreateManyResponse, error) {
+	createdTickets, err := s.uc.ReserveTickets(
+		ctx,
+		req.ShowtimeID,
+		req.MovieID,
+		req.UserID,
+		req.SeatNumbers,
+		dto.ToClientPrice(req.Price, s.currency),
+		req.PromoCode,
+	)
+	if err != nil {
+		s.logError("create many", err)
+		return nil, dto.FromError(err)
+	}
+
+	var ticketsPb []*base.Ticket
+	for _, ticket := range createdTickets {
+		ticketsPb = append(ticketsPb, dto.FromTicketToPb(*ticket))
+	}
+
+	return &svc.CreateManyResponse{
+		Tickets: ticketsPb,
+	}, nil
+}
+
 func (s *TicketServer) Get(ctx context.Context, req *svc.GetRequest) (*svc.GetResponse, error) {
 	ticket, err := s.uc.GetTicket(ctx, req.ID)
 	if err != nil {
@@ -58,89 +87,279 @@ func (s *TicketServer) Get(ctx context.Context, req *svc.GetRequest) (*svc.GetRe
 }
 
 func (s *TicketServer) GetAll(ctx context.Context, req *svc.GetAllRequest) (*svc.GetAllResponse, error) {
-	tickets, err := s.uc.GetAllTickets(ctx)
+	page, err := s.uc.GetAllTickets(
+		ctx,
+		dto.ToPageRequest(req.PageSize, req.PageToken, req.SortBy, req.Descending),
+	)
 	if err != nil {
 		s.logError("get all", err)
 		return nil, dto.FromError(err)
 	}
 
-	var ticketsPb []*base.Ticket
-	for _, ticket := range tickets {
-		ticketsPb = append(ticketsPb, dto.FromTicketToPb(*ticket))
-	}
-
 	return &svc.GetAllResponse{
-		Tickets: ticketsPb,
+		Tickets:       dto.FromTicketPageToPb(*page),
+		NextPageToken: page.NextCursor,
 	}, nil
 }
 
 func (s *TicketServer) GetByUser(ctx context.Context, req *svc.GetByUserRequest) (*svc.GetByUserResponse, error) {
-	tickets, err := s.uc.GetUserTickets(ctx, req.UserID)
+	page, err := s.uc.GetUserTickets(
+		ctx,
+		req.UserID,
+		dto.ToPageRequest(req.PageSize, req.PageToken, req.SortBy, req.Descending),
+	)
 	if err != nil {
 		s.logError("get by user", err)
 		return nil, dto.FromError(err)
 	}
 
-	var ticketsPb []*base.Ticket
-	for _, ticket := range tickets {
-		ticketsPb = append(ticketsPb, dto.FromTicketToPb(*ticket))
-	}
-
 	return &svc.GetByUserResponse{
-		Tickets: ticketsPb,
+		Tickets:       dto.FromTicketPageToPb(*page),
+		NextPageToken: page.NextCursor,
 	}, nil
 }
 
 func (s *TicketServer) GetByMovie(ctx context.Context, req *svc.GetByMovieRequest) (*svc.GetByMovieResponse, error) {
-	tickets, err := s.uc.GetMovieTickets(ctx, req.MovieID)
+	page, err := s.uc.GetMovieTickets(
+		ctx,
+		req.MovieID,
+		dto.ToPageRequest(req.PageSize, req.PageToken, req.SortBy, req.Descending),
+	)
 	if err != nil {
 		s.logError("get by movie", err)
 		return nil, dto.FromError(err)
 	}
 
+	return &svc.GetByMovieResponse{
+		Tickets:       dto.FromTicketPageToPb(*page),
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+func (s *TicketServer) GetBySession(ctx context.Context, req *svc.GetBySessionRequest) (*svc.GetBySessionResponse, error) {
+	tickets, err := s.uc.GetSessionTickets(ctx, req.ShowtimeID, dto.ToTicketStatuses(req.Statuses))
+	if err != nil {
+		s.logError("get by session", err)
+		return nil, dto.FromError(err)
+	}
+
 	var ticketsPb []*base.Ticket
 	for _, ticket := range tickets {
 		ticketsPb = append(ticketsPb, dto.FromTicketToPb(*ticket))
 	}
 
-	return &svc.GetByMovieResponse{
+	return &svc.GetBySessionResponse{
 		Tickets: ticketsPb,
 	}, nil
 }
 
-func (s *TicketServer) Update(ctx context.Context, req *svc.UpdateRequest) (*svc.UpdateResponse, error) {
-	if req.Status != nil && *req.Status == "PAID" {
-		updatedTicket, err := s.uc.ConfirmPayment(ctx, req.ID, "card")
-		if err != nil {
-			s.logError("update", err)
-			return nil, dto.FromError(err)
-		}
+func (s *TicketServer) Search(ctx context.Context, req *svc.SearchRequest) (*svc.SearchResponse, error) {
+	page, err := s.uc.SearchTickets(
+		ctx,
+		dto.ToTicketFilterFromSearchRequest(req, s.currency),
+		dto.ToPageRequest(req.PageSize, req.PageToken, req.SortBy, req.Descending),
+	)
+	if err != nil {
+		s.logError("search", err)
+		return nil, dto.FromError(err)
+	}
 
-		return &svc.UpdateResponse{
-			Ticket: dto.FromTicketToPb(*updatedTicket),
-		}, nil
+	return &svc.SearchResponse{
+		Tickets:       dto.FromTicketPageToPb(*page),
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+// StreamTickets sends tickets as the database cursor yields them. Send
+// blocks while the client's flow-control window is full, which in turn
+// holds back the cursor, and the stream context ends the walk when the
+// client goes away.
+func (s *TicketServer) StreamTickets(req *svc.StreamTicketsRequest, stream svc.TicketService_StreamTicketsServer) error {
+	filterReq := req.Filter
+	if filterReq == nil {
+		filterReq = &svc.SearchRequest{}
 	}
 
-	if req.Status != nil && *req.Status == "CANCELLED" {
-		err := s.uc.CancelTicket(ctx, req.ID)
-		if err != nil {
-			s.logError("update", err)
-			return nil, dto.FromError(err)
-		}
+	ctx := stream.Context()
 
-		ticket, err := s.uc.GetTicket(ctx, req.ID)
-		if err != nil {
-			s.logError("update", err)
-			return nil, dto.FromError(err)
+	err := s.uc.StreamTickets(
+		ctx,
+		dto.ToTicketFilterFromSearchRequest(filterReq, s.currency),
+		dto.ToPageRequest(0, filterReq.PageToken, filterReq.SortBy, filterReq.Descending),
+		func(ticket *models.Ticket) error {
+			return stream.Send(&svc.StreamTicketsResponse{
+				Ticket: dto.FromTicketToPb(*ticket),
+			})
+		},
+	)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
 		}
 
-		return &svc.UpdateResponse{
-			Ticket: dto.FromTicketToPb(*ticket),
-		}, nil
+		s.logError("stream", err)
+		return dto.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *TicketServer) GetSeatMap(ctx context.Context, req *svc.GetSeatMapRequest) (*svc.GetSeatMapResponse, error) {
+	seatMap, err := s.uc.GetSessionSeatMap(ctx, req.ShowtimeID)
+	if err != nil {
+		s.logError("get seat map", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.GetSeatMapResponse{
+		ShowtimeID: seatMap.SessionID,
+		Seats:      dto.FromSeatMapToPb(*seatMap),
+	}, nil
+}
+
+func (s *TicketServer) GetPriceQuote(ctx context.Context, req *svc.GetPriceQuoteRequest) (*svc.GetPriceQuoteResponse, error) {
+	price, err := s.uc.QuotePrice(ctx, req.ShowtimeID, req.MovieID, req.SeatNumber, req.PromoCode)
+	if err != nil {
+		s.logError("get price quote", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.GetPriceQuoteResponse{
+		ShowtimeID: req.ShowtimeID,
+		SeatNumber: req.SeatNumber,
+		Price:      price.Float(),
+		Currency:   price.Currency,
+	}, nil
+}
+
+func (s *TicketServer) GetHistory(ctx context.Context, req *svc.GetHistoryRequest) (*svc.GetHistoryResponse, error) {
+	events, err := s.uc.GetTicketHistory(ctx, req.ID)
+	if err != nil {
+		s.logError("get history", err)
+		return nil, dto.FromError(err)
+	}
+
+	var eventsPb []*svc.TicketEvent
+	for _, event := range events {
+		eventsPb = append(eventsPb, dto.FromTicketEventToPb(*event))
+	}
+
+	return &svc.GetHistoryResponse{
+		Events: eventsPb,
+	}, nil
+}
+
+func (s *TicketServer) GetPayment(ctx context.Context, req *svc.GetPaymentRequest) (*svc.GetPaymentResponse, error) {
+	record, err := s.uc.GetPayment(ctx, req.ID)
+	if err != nil {
+		s.logError("get payment", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.GetPaymentResponse{
+		Payment: dto.FromPaymentToPb(*record),
+	}, nil
+}
+
+func (s *TicketServer) GetPaymentsByTicket(ctx context.Context, req *svc.GetPaymentsByTicketRequest) (*svc.GetPaymentsByTicketResponse, error) {
+	records, err := s.uc.GetTicketPayments(ctx, req.TicketID)
+	if err != nil {
+		s.logError("get payments by ticket", err)
+		return nil, dto.FromError(err)
+	}
+
+	var paymentsPb []*svc.Payment
+	for _, record := range records {
+		paymentsPb = append(paymentsPb, dto.FromPaymentToPb(*record))
+	}
+
+	return &svc.GetPaymentsByTicketResponse{
+		Payments: paymentsPb,
+	}, nil
+}
+
+func (s *TicketServer) CreatePromoCode(ctx context.Context, req *svc.CreatePromoCodeRequest) (*svc.CreatePromoCodeResponse, error) {
+	promo, err := s.uc.CreatePromoCode(ctx, dto.ToPromoCodeFromCreateRequest(req, s.currency))
+	if err != nil {
+		s.logError("create promo code", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.CreatePromoCodeResponse{
+		PromoCode: dto.FromPromoCodeToPb(*promo),
+	}, nil
+}
+
+func (s *TicketServer) GetSalesReport(ctx context.Context, req *svc.GetSalesReportRequest) (*svc.GetSalesReportResponse, error) {
+	from, to, groupBy := dto.ToSalesReportParamsFromRequest(req)
+
+	report, err := s.uc.GetSalesReport(ctx, from, to, groupBy)
+	if err != nil {
+		s.logError("get sales report", err)
+		return nil, dto.FromError(err)
 	}
 
-	s.log.Warn("Update method not fully implemented for this operation")
-	return nil, status.Error(codes.Unimplemented, "Update method not fully implemented")
+	return dto.FromSalesReportToPb(*report), nil
+}
+
+func (s *TicketServer) GetOccupancyReport(ctx context.Context, req *svc.GetOccupancyReportRequest) (*svc.GetOccupancyReportResponse, error) {
+	report, err := s.uc.GetOccupancyReport(ctx, req.ShowtimeID, models.OccupancyInterval(req.Interval))
+	if err != nil {
+		s.logError("get occupancy report", err)
+		return nil, dto.FromError(err)
+	}
+
+	return dto.FromOccupancyReportToPb(*report), nil
+}
+
+func (s *TicketServer) Update(ctx context.Context, req *svc.UpdateRequest) (*svc.UpdateResponse, error) {
+	id, update := dto.ToTicketUpdateFromUpdateRequest(req, s.currency)
+
+	updatedTicket, err := s.uc.UpdateTicket(ctx, id, update)
+	if err != nil {
+		s.logError("update", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.UpdateResponse{
+		Ticket: dto.FromTicketToPb(*updatedTicket),
+	}, nil
+}
+
+func (s *TicketServer) Exchange(ctx context.Context, req *svc.ExchangeRequest) (*svc.ExchangeResponse, error) {
+	exchangedTicket, err := s.uc.ExchangeTicket(ctx, req.ID, req.ShowtimeID, req.SeatNumber)
+	if err != nil {
+		s.logError("exchange", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.ExchangeResponse{
+		Ticket: dto.FromTicketToPb(*exchangedTicket),
+	}, nil
+}
+
+func (s *TicketServer) Transfer(ctx context.Context, req *svc.TransferRequest) (*svc.TransferResponse, error) {
+	transfer, err := s.uc.InitiateTransfer(ctx, req.ID, req.ToUserID)
+	if err != nil {
+		s.logError("transfer", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.TransferResponse{
+		Transfer: dto.FromTicketTransferToPb(*transfer),
+	}, nil
+}
+
+func (s *TicketServer) AcceptTransfer(ctx context.Context, req *svc.AcceptTransferRequest) (*svc.AcceptTransferResponse, error) {
+	ticket, err := s.uc.AcceptTransfer(ctx, req.TransferID)
+	if err != nil {
+		s.logError("accept transfer", err)
+		return nil, dto.FromError(err)
+	}
+
+	return &svc.AcceptTransferResponse{
+		Ticket: dto.FromTicketToPb(*ticket),
+	}, nil
 }
 
 func (s *TicketServer) Delete(ctx context.Context, req *svc.DeleteRequest) (*svc.DeleteResponse, error) {
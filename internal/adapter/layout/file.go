@@ -0,0 +1,166 @@
+package layout
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileLayout struct {
+	Halls    []fileHall    `json:"halls" yaml:"halls"`
+	Schedule []fileSession `json:"schedule" yaml:"schedule"`
+}
+
+type fileHall struct {
+	ID            string    `json:"id" yaml:"id"`
+	Name          string    `json:"name" yaml:"name"`
+	Rows          []fileRow `json:"rows" yaml:"rows"`
+	DisabledSeats []string  `json:"disabledSeats" yaml:"disabledSeats"`
+	Sessions      []string  `json:"sessions" yaml:"sessions"`
+}
+
+// fileRow lists its seats explicitly or, when SeatNumbers is empty,
+// numbers Count seats after the row label.
+type fileRow struct {
+	Label       string   `json:"label" yaml:"label"`
+	Count       int      `json:"count" yaml:"count"`
+	SeatNumbers []string `json:"seatNumbers" yaml:"seatNumbers"`
+}
+
+// fileSession gives a session's start time and, optionally, its own base
+// price as {amount, currency} in minor units.
+type fileSession struct {
+	ID        string        `json:"id" yaml:"id"`
+	MovieID   string        `json:"movieId" yaml:"movieId"`
+	StartsAt  time.Time     `json:"startsAt" yaml:"startsAt"`
+	BasePrice *models.Money `json:"basePrice" yaml:"basePrice"`
+}
+
+// File serves hall layouts read once from a JSON or YAML file.
+type File struct {
+	bySession map[string]models.Hall
+	schedule  *Schedule
+}
+
+// Schedule serves the session start times listed in the layout file.
+type Schedule struct {
+	byID map[string]models.Session
+}
+
+func LoadFile(path string) (*File, error) {
+	const op = "layout.LoadFile"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var raw fileLayout
+
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &raw)
+	default:
+		err = fmt.Errorf("unsupported layout file extension %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	f := &File{
+		bySession: make(map[string]models.Hall),
+		schedule:  &Schedule{byID: make(map[string]models.Session, len(raw.Schedule))},
+	}
+
+	for _, h := range raw.Halls {
+		hall, err := toHallModel(h)
+		if err != nil {
+			return nil, fmt.Errorf("%s: hall %q: %w", op, h.ID, err)
+		}
+
+		for _, sessionID := range h.Sessions {
+			if _, ok := f.bySession[sessionID]; ok {
+				return nil, fmt.Errorf("%s: session %s is linked to more than one hall", op, sessionID)
+			}
+			f.bySession[sessionID] = hall
+		}
+	}
+
+	for _, s := range raw.Schedule {
+		hall, ok := f.bySession[s.ID]
+		if !ok {
+			return nil, fmt.Errorf("%s: scheduled session %s is not linked to a hall", op, s.ID)
+		}
+
+		f.schedule.byID[s.ID] = models.Session{
+			ID:        s.ID,
+			MovieID:   s.MovieID,
+			HallID:    hall.ID,
+			StartsAt:  s.StartsAt,
+			BasePrice: s.BasePrice,
+		}
+	}
+
+	return f, nil
+}
+
+func (f *File) Schedule() *Schedule {
+	return f.schedule
+}
+
+func (f *File) FindBySession(ctx context.Context, sessionID string) (models.Hall, error) {
+	hall, ok := f.bySession[sessionID]
+	if !ok {
+		return models.Hall{}, models.ErrHallNotFound
+	}
+
+	return hall, nil
+}
+
+func (s *Schedule) FindByID(ctx context.Context, sessionID string) (models.Session, error) {
+	session, ok := s.byID[sessionID]
+	if !ok {
+		return models.Session{}, models.ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+func toHallModel(h fileHall) (models.Hall, error) {
+	if h.ID == "" {
+		return models.Hall{}, fmt.Errorf("%w: hall has no id", models.ErrInvalidHallLayout)
+	}
+
+	rows := make([]models.HallRow, len(h.Rows))
+	for i, row := range h.Rows {
+		switch {
+		case len(row.SeatNumbers) > 0:
+			rows[i] = models.HallRow{Label: row.Label, SeatNumbers: row.SeatNumbers}
+		case row.Count > 0:
+			rows[i] = models.NewHallRow(row.Label, row.Count)
+		default:
+			return models.Hall{}, fmt.Errorf("%w: row %q needs seatNumbers or a positive count", models.ErrInvalidHallLayout, row.Label)
+		}
+	}
+
+	hall := models.Hall{
+		ID:            h.ID,
+		Name:          h.Name,
+		Rows:          rows,
+		DisabledSeats: h.DisabledSeats,
+		SessionIDs:    h.Sessions,
+	}
+
+	if err := hall.Validate(); err != nil {
+		return models.Hall{}, err
+	}
+
+	return hall, nil
+}
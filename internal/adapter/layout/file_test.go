@@ -0,0 +1,141 @@
+package layout
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeLayout(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "layout.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeLayout(t, `
+halls:
+  - id: h1
+    rows:
+      - {label: A, count: 3}
+      - {label: B, seatNumbers: [B1, B2]}
+    disabledSeats: [A2]
+    sessions: [s1]
+schedule:
+  - {id: s1, movieId: m1, startsAt: 2026-11-01T18:00:00Z}
+`)
+
+	f, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	hall, err := f.FindBySession(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("FindBySession() error = %v", err)
+	}
+	if got := hall.Capacity(); got != 4 {
+		t.Errorf("Capacity() = %d, want 4", got)
+	}
+
+	session, err := f.Schedule().FindByID(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if session.HallID != "h1" {
+		t.Errorf("session HallID = %q, want %q", session.HallID, "h1")
+	}
+}
+
+func TestLoadFileRejectsInvalidLayouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{
+			name: "seat number repeated across rows",
+			content: `
+halls:
+  - id: h1
+    rows:
+      - {label: A, count: 3}
+      - {label: A2, seatNumbers: [A3]}
+`,
+			wantErr: models.ErrInvalidHallLayout,
+		},
+		{
+			name: "disabled seat outside the layout",
+			content: `
+halls:
+  - id: h1
+    rows: [{label: A, count: 3}]
+    disabledSeats: [Z9]
+`,
+			wantErr: models.ErrInvalidHallLayout,
+		},
+		{
+			name: "zero count",
+			content: `
+halls:
+  - id: h1
+    rows: [{label: A, count: 0}]
+`,
+			wantErr: models.ErrInvalidHallLayout,
+		},
+		{
+			name: "negative count",
+			content: `
+halls:
+  - id: h1
+    rows: [{label: A, count: -2}]
+`,
+			wantErr: models.ErrInvalidHallLayout,
+		},
+		{
+			name: "hall without id",
+			content: `
+halls:
+  - rows: [{label: A, count: 3}]
+`,
+			wantErr: models.ErrInvalidHallLayout,
+		},
+		{
+			name: "session linked to two halls",
+			content: `
+halls:
+  - {id: h1, rows: [{label: A, count: 3}], sessions: [s1]}
+  - {id: h2, rows: [{label: A, count: 3}], sessions: [s1]}
+`,
+		},
+		{
+			name: "scheduled session without a hall",
+			content: `
+halls:
+  - {id: h1, rows: [{label: A, count: 3}], sessions: [s1]}
+schedule:
+  - {id: s2, movieId: m1, startsAt: 2026-11-01T18:00:00Z}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFile(writeLayout(t, tt.content))
+			if err == nil {
+				t.Fatalf("LoadFile() error = nil, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadFile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/adapter/mongo/dao"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Analytics runs reporting aggregations over the tickets collection.
+// Days are bucketed in timezone, an IANA name such as "Asia/Almaty".
+type Analytics struct {
+	col      *mongo.Collection
+	timezone string
+}
+
+func NewAnalytics(conn *mongo.Database, timezone string) *Analytics {
+	collection := conn.Collection(collectionTickets)
+
+	return &Analytics{col: collection, timezone: timezone}
+}
+
+// SalesReport buckets each kind of ticket activity by when it happened:
+// reservations by creation time, sales by purchase time, refunds by refund
+// time and the price differences of paid exchanges by exchange time.
+// Reservations and sales count toward the session and price the ticket had
+// then, and exchange differences toward the session it moved to.
+func (db *Analytics) SalesReport(ctx context.Context, query models.SalesReportQuery) ([]models.SalesReportRow, error) {
+	period := bson.M{"$gte": query.From, "$lt": query.To}
+
+	sumBy := func(match bson.M, session, date, currency, count, amount any) bson.A {
+		return bson.A{
+			bson.M{"$match": match},
+			bson.M{"$group": bson.M{
+				"_id": bson.M{
+					"key":      db.groupKey(query.GroupBy, session, date),
+					"currency": currency,
+				},
+				"count":  bson.M{"$sum": count},
+				"amount": bson.M{"$sum": amount},
+			}},
+		}
+	}
+
+	paid := bson.M{"$in": models.PaidTicketStatuses()}
+
+	// The seat a ticket was bought for is the first it left while paid, or
+	// its current one.
+	purchased := func(field string) bson.M {
+		return bson.M{"$let": bson.M{
+			"vars": bson.M{"seat": bson.M{"$arrayElemAt": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$exchanges", bson.A{}}},
+					"cond":  bson.M{"$eq": bson.A{"$$this.status", models.TicketStatusPaid}},
+				}},
+				0,
+			}}},
+			"in": bson.M{"$ifNull": bson.A{"$$seat." + field, "$" + field}},
+		}}
+	}
+	reservedIn := bson.M{"$ifNull": bson.A{
+		bson.M{"$arrayElemAt": bson.A{"$exchanges.session_id", 0}},
+		"$session_id",
+	}}
+
+	paidExchange := bson.M{
+		"exchanges.status":       models.TicketStatusPaid,
+		"exchanges.exchanged_at": period,
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"created_at": period},
+			bson.M{"purchase_time": period, "status": paid},
+			bson.M{"refunded_at": period, "status": models.TicketStatusRefunded},
+			bson.M{"exchanges": bson.M{"$elemMatch": bson.M{
+				"status":       models.TicketStatusPaid,
+				"exchanged_at": period,
+			}}},
+		}}}},
+		{{Key: "$facet", Value: bson.M{
+			"reserved": sumBy(
+				bson.M{"created_at": period},
+				reservedIn, "$created_at", "$price.currency", 1, 0,
+			),
+			"sold": sumBy(
+				bson.M{"purchase_time": period, "status": paid},
+				purchased("session_id"), "$purchase_time", purchased("price.currency"), 1, purchased("price.amount"),
+			),
+			"refunded": sumBy(
+				bson.M{"refunded_at": period, "status": models.TicketStatusRefunded},
+				"$session_id", "$refunded_at", "$price.currency", 1,
+				bson.M{"$ifNull": bson.A{"$refund_amount.amount", 0}},
+			),
+			"exchanged": append(
+				bson.A{bson.M{"$unwind": "$exchanges"}},
+				sumBy(
+					paidExchange,
+					"$exchanges.new_session_id", "$exchanges.exchanged_at", "$exchanges.price.currency", 0,
+					bson.M{"$subtract": bson.A{"$exchanges.new_price.amount", "$exchanges.price.amount"}},
+				)...,
+			),
+		}}},
+	}
+
+	cur, err := db.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, mongoError("Aggregate", err)
+	}
+
+	var facets []dao.SalesReportFacets
+	if err = cur.All(ctx, &facets); err != nil {
+		return nil, mongoError("Cursor.All", err)
+	}
+
+	if len(facets) == 0 {
+		return nil, nil
+	}
+
+	return dao.ToSalesReportRowModels(facets[0]), nil
+}
+
+// SessionOccupancy buckets a session's seat changes by interval. Each
+// stretch of time a ticket held a seat in the session counts: it takes the
+// seat when reserved or exchanged in, is sold when paid or when it arrives
+// already paid, and gives the seat back when cancelled, expired, refunded
+// or exchanged away.
+func (db *Analytics) SessionOccupancy(
+	ctx context.Context,
+	sessionID string,
+	interval models.OccupancyInterval,
+) ([]models.OccupancyDelta, error) {
+	objID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	bucket := func(field string) bson.M {
+		return bson.M{"$dateTrunc": bson.M{
+			"date":     field,
+			"unit":     string(interval),
+			"timezone": db.timezone,
+		}}
+	}
+	countBy := func(match bson.M, field string) bson.A {
+		return bson.A{
+			bson.M{"$match": match},
+			bson.M{"$group": bson.M{"_id": bucket(field), "count": bson.M{"$sum": 1}}},
+		}
+	}
+
+	paidTicket := models.TicketStatusPaid
+
+	// Seats the ticket gave up in this session by exchange.
+	exchangedAway := bson.M{"$map": bson.M{
+		"input": bson.M{"$filter": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$exchanges", bson.A{}}},
+			"cond":  bson.M{"$eq": bson.A{"$$this.session_id", objID}},
+		}},
+		"as": "e",
+		"in": bson.M{
+			"from": "$$e.held_from",
+			"paid_at": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$$e.status", paidTicket}},
+				bson.M{"$max": bson.A{"$purchase_time", "$$e.held_from"}},
+				nil,
+			}},
+			"released_at": "$$e.exchanged_at",
+			"sold":        bson.M{"$eq": bson.A{"$$e.status", paidTicket}},
+		},
+	}}
+
+	// The seat the ticket holds now, if it is in this session.
+	heldSince := bson.M{"$ifNull": bson.A{
+		bson.M{"$arrayElemAt": bson.A{"$exchanges.exchanged_at", -1}},
+		"$created_at",
+	}}
+	current := bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{"$session_id", objID}},
+		bson.A{bson.M{
+			"from": heldSince,
+			"paid_at": bson.M{"$cond": bson.A{
+				bson.M{"$in": bson.A{"$status", models.PaidTicketStatuses()}},
+				bson.M{"$max": bson.A{"$purchase_time", heldSince}},
+				nil,
+			}},
+			"released_at": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{
+						"case": bson.M{"$in": bson.A{"$status", bson.A{models.TicketStatusCancelled, models.TicketStatusExpired}}},
+						"then": "$released_at",
+					},
+					bson.M{
+						"case": bson.M{"$eq": bson.A{"$status", models.TicketStatusRefunded}},
+						"then": "$refunded_at",
+					},
+				},
+				"default": nil,
+			}},
+			"sold": bson.M{"$eq": bson.A{"$status", models.TicketStatusRefunded}},
+		}},
+		bson.A{},
+	}}
+
+	released := bson.M{"$ne": nil}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"session_id": objID},
+			bson.M{"exchanges.session_id": objID},
+		}}}},
+		{{Key: "$project", Value: bson.M{"stint": bson.M{"$concatArrays": bson.A{exchangedAway, current}}}}},
+		{{Key: "$unwind", Value: "$stint"}},
+		{{Key: "$facet", Value: bson.M{
+			"reserved":      countBy(bson.M{}, "$stint.from"),
+			"paid":          countBy(bson.M{"stint.paid_at": bson.M{"$ne": nil}}, "$stint.paid_at"),
+			"released_held": countBy(bson.M{"stint.released_at": released, "stint.sold": false}, "$stint.released_at"),
+			"released_sold": countBy(bson.M{"stint.released_at": released, "stint.sold": true}, "$stint.released_at"),
+		}}},
+	}
+
+	cur, err := db.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, mongoError("Aggregate", err)
+	}
+
+	var facets []dao.OccupancyFacets
+	if err = cur.All(ctx, &facets); err != nil {
+		return nil, mongoError("Cursor.All", err)
+	}
+
+	if len(facets) == 0 {
+		return nil, nil
+	}
+
+	return dao.ToOccupancyDeltaModels(facets[0]), nil
+}
+
+// groupKey returns the report group of a ticket: its movie, the session
+// given by session, or the day of date.
+func (db *Analytics) groupKey(groupBy models.ReportGroupBy, session, date any) any {
+	switch groupBy {
+	case models.ReportGroupByMovie:
+		return bson.M{"$toString": "$movie_id"}
+	case models.ReportGroupBySession:
+		return bson.M{"$toString": session}
+	default:
+		return bson.M{"$dateToString": bson.M{
+			"format":   "%Y-%m-%d",
+			"date":     date,
+			"timezone": db.timezone,
+		}}
+	}
+}
@@ -0,0 +1,225 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnalyticsSalesReport(t *testing.T) {
+	tickets := newTestTickets(t)
+	analytics := NewAnalytics(tickets.col.Database(), "UTC")
+	ctx := context.Background()
+
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC) }
+	oldSession := primitive.NewObjectID().Hex()
+	newSession := primitive.NewObjectID().Hex()
+
+	// Bought on the 1st, moved to a dearer seat on the 2nd and refunded on
+	// the 3rd; an unrelated later write must not move the refund.
+	exchanged := newTestTicket(newSession, "A2")
+	exchanged.Status = models.TicketStatusRefunded
+	exchanged.Price = models.NewMoney(3000, "KZT")
+	exchanged.CreatedAt = day(1, 9)
+	exchanged.PurchaseTime = day(1, 10)
+	exchanged.RefundAmount = models.NewMoney(3000, "KZT").Ptr()
+	exchanged.RefundedAt = day(3, 12)
+	exchanged.UpdatedAt = day(5, 12)
+	exchanged.Exchanges = []models.Exchange{{
+		SessionID:    oldSession,
+		SeatNumber:   "A1",
+		Price:        models.NewMoney(2500, "KZT"),
+		Status:       models.TicketStatusPaid,
+		HeldFrom:     day(1, 9),
+		ExchangedAt:  day(2, 12),
+		NewSessionID: newSession,
+		NewPrice:     models.NewMoney(3000, "KZT"),
+	}}
+
+	reserved := newTestTicket(oldSession, "A3")
+	reserved.CreatedAt = day(1, 11)
+
+	for _, ticket := range []models.Ticket{exchanged, reserved} {
+		if _, err := tickets.InsertOne(ctx, &ticket); err != nil {
+			t.Fatalf("InsertOne() error = %v", err)
+		}
+	}
+
+	rows, err := analytics.SalesReport(ctx, models.SalesReportQuery{
+		From:    day(1, 0),
+		To:      day(6, 0),
+		GroupBy: models.ReportGroupByDay,
+	})
+	if err != nil {
+		t.Fatalf("SalesReport() error = %v", err)
+	}
+
+	got := make(map[string]models.SalesReportRow)
+	for _, row := range rows {
+		got[row.Key] = row
+	}
+
+	if row := got["2026-03-01"]; row.Reserved != 2 || row.Paid != 1 || row.Revenue.Amount != 2500 {
+		t.Errorf("1 March = %+v, want 2 reserved and 1 sold for 2500", row)
+	}
+	if row := got["2026-03-02"]; row.Paid != 0 || row.Revenue.Amount != 500 {
+		t.Errorf("2 March = %+v, want the 500 exchange surcharge", row)
+	}
+	if row := got["2026-03-03"]; row.Refunded.Amount != 3000 {
+		t.Errorf("3 March = %+v, want 3000 refunded", row)
+	}
+	if _, ok := got["2026-03-05"]; ok {
+		t.Errorf("refund was bucketed by the ticket's last update")
+	}
+
+	rows, err = analytics.SalesReport(ctx, models.SalesReportQuery{
+		From:    day(1, 0),
+		To:      day(6, 0),
+		GroupBy: models.ReportGroupBySession,
+	})
+	if err != nil {
+		t.Fatalf("SalesReport() error = %v", err)
+	}
+
+	got = make(map[string]models.SalesReportRow)
+	for _, row := range rows {
+		got[row.Key] = row
+	}
+
+	if row := got[oldSession]; row.Reserved != 2 || row.Paid != 1 || row.Revenue.Amount != 2500 {
+		t.Errorf("old session = %+v, want both reservations and the sale at 2500", row)
+	}
+	if row := got[newSession]; row.Paid != 0 || row.Revenue.Amount != 500 || row.Refunded.Amount != 3000 {
+		t.Errorf("new session = %+v, want the 500 surcharge and the 3000 refund", row)
+	}
+}
+
+func TestTicketMigrateRefundTimes(t *testing.T) {
+	tickets := newTestTickets(t)
+	ctx := context.Background()
+
+	refundedAt := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+
+	logged := newTestTicket(primitive.NewObjectID().Hex(), "A1")
+	logged.Status = models.TicketStatusRefunded
+	logged.UpdatedAt = updatedAt
+	unlogged := logged
+	unlogged.SeatNumber = "A2"
+
+	var ids []primitive.ObjectID
+	for _, ticket := range []models.Ticket{logged, unlogged} {
+		inserted, err := tickets.InsertOne(ctx, &ticket)
+		if err != nil {
+			t.Fatalf("InsertOne() error = %v", err)
+		}
+		id, _ := primitive.ObjectIDFromHex(inserted.ID)
+		ids = append(ids, id)
+	}
+
+	// Only the first refund made it into the event log.
+	events := tickets.col.Database().Collection(collectionTicketEvents)
+	if _, err := events.InsertOne(ctx, bson.M{
+		"ticket_id":  ids[0],
+		"new_status": models.TicketStatusRefunded,
+		"created_at": refundedAt,
+	}); err != nil {
+		t.Fatalf("InsertOne() event error = %v", err)
+	}
+
+	migrated, err := tickets.MigrateRefundTimes(ctx)
+	if err != nil {
+		t.Fatalf("MigrateRefundTimes() error = %v", err)
+	}
+	if migrated != 2 {
+		t.Errorf("MigrateRefundTimes() = %d, want 2", migrated)
+	}
+
+	for i, want := range []time.Time{refundedAt, updatedAt} {
+		id := ids[i].Hex()
+		ticket, err := tickets.FindOne(ctx, models.TicketFilter{ID: &id})
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if !ticket.RefundedAt.Equal(want) {
+			t.Errorf("ticket %d refunded at %v, want %v", i, ticket.RefundedAt, want)
+		}
+	}
+
+	if migrated, err := tickets.MigrateRefundTimes(ctx); err != nil || migrated != 0 {
+		t.Errorf("second MigrateRefundTimes() = %d, %v; want 0, nil", migrated, err)
+	}
+}
+
+func TestAnalyticsSessionOccupancy(t *testing.T) {
+	tickets := newTestTickets(t)
+	analytics := NewAnalytics(tickets.col.Database(), "UTC")
+	ctx := context.Background()
+
+	hour := func(h int) time.Time { return time.Date(2026, 3, 1, h, 0, 0, 0, time.UTC) }
+	oldSession := primitive.NewObjectID().Hex()
+	newSession := primitive.NewObjectID().Hex()
+
+	// Reserved at 9, paid at 10 and exchanged into the new session at 11.
+	moved := newTestTicket(newSession, "A1")
+	moved.Status = models.TicketStatusPaid
+	moved.CreatedAt = hour(9)
+	moved.PurchaseTime = hour(10)
+	moved.UpdatedAt = hour(11)
+	moved.Exchanges = []models.Exchange{{
+		SessionID:    oldSession,
+		SeatNumber:   "A1",
+		Price:        moved.Price,
+		Status:       models.TicketStatusPaid,
+		HeldFrom:     hour(9),
+		ExchangedAt:  hour(11),
+		NewSessionID: newSession,
+		NewPrice:     moved.Price,
+	}}
+
+	// Reserved at 9 and cancelled at 10; a later write must not move the release.
+	cancelled := newTestTicket(oldSession, "A2")
+	cancelled.Status = models.TicketStatusCancelled
+	cancelled.CreatedAt = hour(9)
+	cancelled.ReleasedAt = hour(10)
+	cancelled.UpdatedAt = hour(14)
+
+	for _, ticket := range []models.Ticket{moved, cancelled} {
+		if _, err := tickets.InsertOne(ctx, &ticket); err != nil {
+			t.Fatalf("InsertOne() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		sessionID string
+		want      []models.OccupancyDelta
+	}{
+		{
+			sessionID: oldSession,
+			want: []models.OccupancyDelta{
+				{At: hour(9), Reserved: 2},
+				{At: hour(10), Paid: 1, ReleasedHeld: 1},
+				{At: hour(11), ReleasedSold: 1},
+			},
+		},
+		{
+			sessionID: newSession,
+			want:      []models.OccupancyDelta{{At: hour(11), Reserved: 1, Paid: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := analytics.SessionOccupancy(ctx, tt.sessionID, models.OccupancyIntervalHour)
+		if err != nil {
+			t.Fatalf("SessionOccupancy() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SessionOccupancy(%s) =\n%+v\nwant\n%+v", tt.sessionID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// ticketCursor remembers where a page ended: the sort key and _id of its
+// last ticket, plus the sort it was produced with. Times are kept as Unix
+// microseconds, which covers the zero purchase time of unpaid tickets.
+type ticketCursor struct {
+	SortBy     models.TicketSortField `json:"s"`
+	Descending bool                   `json:"d"`
+	Value      int64                  `json:"v"`
+	ID         string                 `json:"id"`
+}
+
+// TicketSortKey returns the document field a listing is sorted on.
+func TicketSortKey(sortBy models.TicketSortField) string {
+	switch sortBy {
+	case models.TicketSortPurchaseTime:
+		return "purchase_time"
+	case models.TicketSortPrice:
+		return "price.amount"
+	default:
+		return "created_at"
+	}
+}
+
+func EncodeTicketCursor(ticket Ticket, page models.PageRequest) string {
+	c := ticketCursor{
+		SortBy:     page.SortBy,
+		Descending: page.Descending,
+		ID:         ticket.ID.Hex(),
+	}
+
+	switch page.SortBy {
+	case models.TicketSortPurchaseTime:
+		c.Value = ticket.PurchaseTime.UnixMicro()
+	case models.TicketSortPrice:
+		c.Value = ticket.Price.Amount
+	default:
+		c.Value = ticket.CreatedAt.UnixMicro()
+	}
+
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// FromTicketCursor builds the condition selecting tickets after the cursor
+// in page's sort order, ties broken by _id.
+func FromTicketCursor(token string, page models.PageRequest) (bson.M, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+
+	var c ticketCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+
+	if c.SortBy != page.SortBy || c.Descending != page.Descending {
+		return nil, models.ErrInvalidCursor
+	}
+
+	objID, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+
+	var value any = c.Value
+	if page.SortBy != models.TicketSortPrice {
+		value = time.UnixMicro(c.Value).UTC()
+	}
+
+	cmp := "$gt"
+	if page.Descending {
+		cmp = "$lt"
+	}
+
+	key := TicketSortKey(page.SortBy)
+
+	return bson.M{
+		"$or": bson.A{
+			bson.M{key: bson.M{cmp: value}},
+			bson.M{key: value, "_id": bson.M{cmp: objID}},
+		},
+	}, nil
+}
@@ -0,0 +1,45 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type TicketEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	TicketID  primitive.ObjectID `bson:"ticket_id"`
+	Actor     string             `bson:"actor"`
+	Reason    string             `bson:"reason"`
+	OldStatus string             `bson:"old_status"`
+	NewStatus string             `bson:"new_status"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+func FromTicketEventModel(event models.TicketEvent) (TicketEvent, error) {
+	ticketID, err := primitive.ObjectIDFromHex(event.TicketID)
+	if err != nil {
+		return TicketEvent{}, err
+	}
+
+	return TicketEvent{
+		TicketID:  ticketID,
+		Actor:     event.Actor,
+		Reason:    event.Reason,
+		OldStatus: string(event.OldStatus),
+		NewStatus: string(event.NewStatus),
+		CreatedAt: event.CreatedAt,
+	}, nil
+}
+
+func ToTicketEventModel(event TicketEvent) models.TicketEvent {
+	return models.TicketEvent{
+		ID:        event.ID.Hex(),
+		TicketID:  event.TicketID.Hex(),
+		Actor:     event.Actor,
+		Reason:    event.Reason,
+		OldStatus: models.TicketStatus(event.OldStatus),
+		NewStatus: models.TicketStatus(event.NewStatus),
+		CreatedAt: event.CreatedAt,
+	}
+}
@@ -0,0 +1,42 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Hall struct {
+	ID            primitive.ObjectID   `bson:"_id,omitempty"`
+	Name          string               `bson:"name"`
+	Rows          []HallRow            `bson:"rows"`
+	DisabledSeats []string             `bson:"disabled_seats"`
+	SessionIDs    []primitive.ObjectID `bson:"session_ids"`
+}
+
+type HallRow struct {
+	Label       string   `bson:"label"`
+	SeatNumbers []string `bson:"seat_numbers"`
+}
+
+func ToHallModel(hall Hall) models.Hall {
+	rows := make([]models.HallRow, len(hall.Rows))
+	for i, row := range hall.Rows {
+		rows[i] = models.HallRow{
+			Label:       row.Label,
+			SeatNumbers: row.SeatNumbers,
+		}
+	}
+
+	sessionIDs := make([]string, len(hall.SessionIDs))
+	for i, id := range hall.SessionIDs {
+		sessionIDs[i] = id.Hex()
+	}
+
+	return models.Hall{
+		ID:            hall.ID.Hex(),
+		Name:          hall.Name,
+		Rows:          rows,
+		DisabledSeats: hall.DisabledSeats,
+		SessionIDs:    sessionIDs,
+	}
+}
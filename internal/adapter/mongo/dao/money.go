@@ -0,0 +1,41 @@
+package dao
+
+import "ap2final_ticket_service/internal/models"
+
+// Money is stored as an integer amount of minor units next to its currency.
+type Money struct {
+	Amount   int64  `bson:"amount"`
+	Currency string `bson:"currency"`
+}
+
+func FromMoneyModel(money models.Money) Money {
+	return Money{
+		Amount:   money.Amount,
+		Currency: money.Currency,
+	}
+}
+
+func ToMoneyModel(money Money) models.Money {
+	return models.Money{
+		Amount:   money.Amount,
+		Currency: money.Currency,
+	}
+}
+
+func fromMoneyModelPtr(money *models.Money) *Money {
+	if money == nil {
+		return nil
+	}
+
+	m := FromMoneyModel(*money)
+	return &m
+}
+
+func toMoneyModelPtr(money *Money) *models.Money {
+	if money == nil {
+		return nil
+	}
+
+	m := ToMoneyModel(*money)
+	return &m
+}
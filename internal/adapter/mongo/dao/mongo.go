@@ -4,6 +4,7 @@ import (
 	"ap2final_ticket_service/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
 	"time"
 )
 
@@ -12,15 +13,103 @@ type Ticket struct {
 	SessionID     primitive.ObjectID `bson:"session_id"`
 	MovieID       primitive.ObjectID `bson:"movie_id"`
 	SeatNumber    string             `bson:"seat_number"`
-	Price         float64            `bson:"price"`
+	Price         Money              `bson:"price"`
 	Status        string             `bson:"status"`
 	UserID        primitive.ObjectID `bson:"user_id"`
 	PurchaseTime  time.Time          `bson:"purchase_time"`
 	PaymentMethod string             `bson:"payment_method"`
+	PaymentID     *string            `bson:"payment_id,omitempty"`
+	RefundID      *string            `bson:"refund_id,omitempty"`
+	RefundAmount  *Money             `bson:"refund_amount,omitempty"`
+	RefundedAt    time.Time          `bson:"refunded_at,omitempty"`
+	ReleasedAt    time.Time          `bson:"released_at,omitempty"`
+	PromoCode     *string            `bson:"promo_code,omitempty"`
+	Discount      *Money             `bson:"discount,omitempty"`
+	PendingRefund *PendingRefund     `bson:"pending_refund,omitempty"`
+	Exchanges     []Exchange         `bson:"exchanges,omitempty"`
+	ExpiresAt     time.Time          `bson:"expires_at,omitempty"`
+	Version       int64              `bson:"version"`
 	CreatedAt     time.Time          `bson:"created_at"`
 	UpdatedAt     time.Time          `bson:"updated_at"`
 }
 
+type PendingRefund struct {
+	Amount Money  `bson:"amount"`
+	Key    string `bson:"key"`
+}
+
+func fromPendingRefundModel(refund *models.PendingRefund) *PendingRefund {
+	if refund == nil || refund.Amount.Amount == 0 {
+		return nil
+	}
+
+	return &PendingRefund{Amount: FromMoneyModel(refund.Amount), Key: refund.Key}
+}
+
+func toPendingRefundModel(refund *PendingRefund) *models.PendingRefund {
+	if refund == nil {
+		return nil
+	}
+
+	return &models.PendingRefund{Amount: ToMoneyModel(refund.Amount), Key: refund.Key}
+}
+
+type Exchange struct {
+	SessionID    primitive.ObjectID `bson:"session_id"`
+	SeatNumber   string             `bson:"seat_number"`
+	Price        Money              `bson:"price"`
+	Status       string             `bson:"status"`
+	HeldFrom     time.Time          `bson:"held_from"`
+	ExchangedAt  time.Time          `bson:"exchanged_at"`
+	NewSessionID primitive.ObjectID `bson:"new_session_id"`
+	NewPrice     Money              `bson:"new_price"`
+}
+
+func fromExchangeModel(exchange models.Exchange) (Exchange, error) {
+	sessionID, err := primitive.ObjectIDFromHex(exchange.SessionID)
+	if err != nil {
+		return Exchange{}, err
+	}
+
+	newSessionID, err := primitive.ObjectIDFromHex(exchange.NewSessionID)
+	if err != nil {
+		return Exchange{}, err
+	}
+
+	return Exchange{
+		SessionID:    sessionID,
+		SeatNumber:   exchange.SeatNumber,
+		Price:        FromMoneyModel(exchange.Price),
+		Status:       string(exchange.Status),
+		HeldFrom:     exchange.HeldFrom,
+		ExchangedAt:  exchange.ExchangedAt,
+		NewSessionID: newSessionID,
+		NewPrice:     FromMoneyModel(exchange.NewPrice),
+	}, nil
+}
+
+func toExchangeModels(exchanges []Exchange) []models.Exchange {
+	if len(exchanges) == 0 {
+		return nil
+	}
+
+	result := make([]models.Exchange, len(exchanges))
+	for i, exchange := range exchanges {
+		result[i] = models.Exchange{
+			SessionID:    exchange.SessionID.Hex(),
+			SeatNumber:   exchange.SeatNumber,
+			Price:        ToMoneyModel(exchange.Price),
+			Status:       models.TicketStatus(exchange.Status),
+			HeldFrom:     exchange.HeldFrom,
+			ExchangedAt:  exchange.ExchangedAt,
+			NewSessionID: exchange.NewSessionID.Hex(),
+			NewPrice:     ToMoneyModel(exchange.NewPrice),
+		}
+	}
+
+	return result
+}
+
 func FromModel(ticket models.Ticket) (Ticket, error) {
 	var objID primitive.ObjectID
 	var err error
@@ -47,16 +136,36 @@ func FromModel(ticket models.Ticket) (Ticket, error) {
 		return Ticket{}, err
 	}
 
+	var exchanges []Exchange
+	for _, exchange := range ticket.Exchanges {
+		exchangeDao, err := fromExchangeModel(exchange)
+		if err != nil {
+			return Ticket{}, err
+		}
+		exchanges = append(exchanges, exchangeDao)
+	}
+
 	return Ticket{
 		ID:            objID,
 		SessionID:     sessionID,
 		MovieID:       movieID,
 		SeatNumber:    ticket.SeatNumber,
-		Price:         ticket.Price,
+		Price:         FromMoneyModel(ticket.Price),
 		Status:        string(ticket.Status),
 		UserID:        userID,
 		PurchaseTime:  ticket.PurchaseTime,
 		PaymentMethod: ticket.PaymentMethod,
+		PaymentID:     ticket.PaymentID,
+		RefundID:      ticket.RefundID,
+		RefundAmount:  fromMoneyModelPtr(ticket.RefundAmount),
+		RefundedAt:    ticket.RefundedAt,
+		ReleasedAt:    ticket.ReleasedAt,
+		PromoCode:     ticket.PromoCode,
+		Discount:      fromMoneyModelPtr(ticket.Discount),
+		PendingRefund: fromPendingRefundModel(ticket.PendingRefund),
+		Exchanges:     exchanges,
+		ExpiresAt:     ticket.ExpiresAt,
+		Version:       ticket.Version,
 		CreatedAt:     ticket.CreatedAt,
 		UpdatedAt:     ticket.UpdatedAt,
 	}, nil
@@ -68,11 +177,22 @@ func ToModel(ticket Ticket) models.Ticket {
 		SessionID:     ticket.SessionID.Hex(),
 		MovieID:       ticket.MovieID.Hex(),
 		SeatNumber:    ticket.SeatNumber,
-		Price:         ticket.Price,
+		Price:         ToMoneyModel(ticket.Price),
 		Status:        models.TicketStatus(ticket.Status),
 		UserID:        ticket.UserID.Hex(),
 		PurchaseTime:  ticket.PurchaseTime,
 		PaymentMethod: ticket.PaymentMethod,
+		PaymentID:     ticket.PaymentID,
+		RefundID:      ticket.RefundID,
+		RefundAmount:  toMoneyModelPtr(ticket.RefundAmount),
+		RefundedAt:    ticket.RefundedAt,
+		ReleasedAt:    ticket.ReleasedAt,
+		PromoCode:     ticket.PromoCode,
+		Discount:      toMoneyModelPtr(ticket.Discount),
+		PendingRefund: toPendingRefundModel(ticket.PendingRefund),
+		Exchanges:     toExchangeModels(ticket.Exchanges),
+		ExpiresAt:     ticket.ExpiresAt,
+		Version:       ticket.Version,
 		CreatedAt:     ticket.CreatedAt,
 		UpdatedAt:     ticket.UpdatedAt,
 	}
@@ -125,41 +245,177 @@ func FromTicketFilter(filter models.TicketFilter) (bson.M, error) {
 		query["user_id"] = userID
 	}
 
-	if filter.SeatNumber != nil {
-		query["seat_number"] = *filter.SeatNumber
+	if filter.SeatNumber != nil || filter.SeatPrefix != nil {
+		seat := bson.M{}
+		if filter.SeatNumber != nil {
+			seat["$eq"] = *filter.SeatNumber
+		}
+		if filter.SeatPrefix != nil {
+			// An anchored literal prefix can use the session/seat index.
+			seat["$regex"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(*filter.SeatPrefix)}
+		}
+		query["seat_number"] = seat
 	}
 
-	if filter.Status != nil {
-		query["status"] = *filter.Status
+	if filter.Status != nil || len(filter.Statuses) > 0 {
+		status := bson.M{}
+		if filter.Status != nil {
+			status["$eq"] = *filter.Status
+		}
+		if len(filter.Statuses) > 0 {
+			status["$in"] = filter.Statuses
+		}
+		query["status"] = status
 	}
 
 	if filter.PaymentMethod != nil {
 		query["payment_method"] = *filter.PaymentMethod
 	}
 
+	if filter.Version != nil {
+		// Tickets written before versioning have no version field and count as 0.
+		if *filter.Version == 0 {
+			query["version"] = bson.M{"$in": bson.A{0, nil}}
+		} else {
+			query["version"] = *filter.Version
+		}
+	}
+
+	if filter.ExpiresBefore != nil {
+		query["expires_at"] = bson.M{"$lte": *filter.ExpiresBefore}
+	}
+
+	if filter.PendingRefund != nil {
+		// Settled refunds are cleared to null rather than removed.
+		if *filter.PendingRefund {
+			query["pending_refund"] = bson.M{"$ne": nil}
+		} else {
+			query["pending_refund"] = nil
+		}
+	}
+
+	if r := timeRange(filter.CreatedFrom, filter.CreatedTo); r != nil {
+		query["created_at"] = r
+	}
+
+	if r := timeRange(filter.PurchasedFrom, filter.PurchasedTo); r != nil {
+		query["purchase_time"] = r
+	}
+
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		amount := bson.M{}
+		if filter.MinPrice != nil {
+			amount["$gte"] = filter.MinPrice.Amount
+			query["price.currency"] = filter.MinPrice.Currency
+		}
+		if filter.MaxPrice != nil {
+			amount["$lte"] = filter.MaxPrice.Amount
+			query["price.currency"] = filter.MaxPrice.Currency
+		}
+		query["price.amount"] = amount
+	}
+
 	return query, nil
 }
 
-func FromTicketUpdateData(update models.TicketUpdateData) bson.M {
+// timeRange builds an inclusive range condition, or nil when both ends are open.
+func timeRange(from, to *time.Time) bson.M {
+	if from == nil && to == nil {
+		return nil
+	}
+
+	r := bson.M{}
+	if from != nil {
+		r["$gte"] = *from
+	}
+	if to != nil {
+		r["$lte"] = *to
+	}
+
+	return r
+}
+
+func FromTicketUpdateData(update models.TicketUpdateData) (bson.M, error) {
 	query := bson.M{}
 
 	if update.Status != nil {
 		query["status"] = *update.Status
 	}
 
+	if update.SessionID != nil {
+		sessionID, err := primitive.ObjectIDFromHex(*update.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		query["session_id"] = sessionID
+	}
+
+	if update.SeatNumber != nil {
+		query["seat_number"] = *update.SeatNumber
+	}
+
+	if update.UserID != nil {
+		userID, err := primitive.ObjectIDFromHex(*update.UserID)
+		if err != nil {
+			return nil, err
+		}
+		query["user_id"] = userID
+	}
+
 	if update.PaymentMethod != nil {
 		query["payment_method"] = *update.PaymentMethod
 	}
 
+	if update.PaymentID != nil {
+		query["payment_id"] = *update.PaymentID
+	}
+
 	if update.PurchaseTime != nil {
 		query["purchase_time"] = *update.PurchaseTime
 	}
 
 	if update.Price != nil {
-		query["price"] = *update.Price
+		query["price"] = FromMoneyModel(*update.Price)
+	}
+
+	if update.Discount != nil {
+		query["discount"] = FromMoneyModel(*update.Discount)
+	}
+
+	if update.PendingRefund != nil {
+		query["pending_refund"] = fromPendingRefundModel(update.PendingRefund)
+	}
+
+	if update.RefundID != nil {
+		query["refund_id"] = *update.RefundID
+	}
+
+	if update.RefundAmount != nil {
+		query["refund_amount"] = FromMoneyModel(*update.RefundAmount)
+	}
+
+	if update.RefundedAt != nil {
+		query["refunded_at"] = *update.RefundedAt
+	}
+
+	if update.ReleasedAt != nil {
+		query["released_at"] = *update.ReleasedAt
 	}
 
 	query["updated_at"] = time.Now()
 
-	return bson.M{"$set": query}
+	result := bson.M{
+		"$set": query,
+		"$inc": bson.M{"version": 1},
+	}
+
+	if update.Exchange != nil {
+		exchange, err := fromExchangeModel(*update.Exchange)
+		if err != nil {
+			return nil, err
+		}
+		result["$push"] = bson.M{"exchanges": exchange}
+	}
+
+	return result, nil
 }
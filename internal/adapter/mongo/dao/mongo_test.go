@@ -0,0 +1,110 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromTicketFilterSearch(t *testing.T) {
+	sessionHex := "665f1c2e8b3e4a0012345678"
+	sessionID, _ := primitive.ObjectIDFromHex(sessionHex)
+	seat := "B7"
+	prefix := "B.1"
+	method := "card"
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	low := models.NewMoney(1000, "KZT")
+	high := models.NewMoney(5000, "KZT")
+
+	tests := []struct {
+		name   string
+		filter models.TicketFilter
+		want   bson.M
+	}{
+		{name: "empty", filter: models.TicketFilter{}, want: bson.M{}},
+		{
+			name:   "seat prefix is an anchored literal",
+			filter: models.TicketFilter{SessionID: &sessionHex, SeatPrefix: &prefix},
+			want: bson.M{
+				"session_id":  sessionID,
+				"seat_number": bson.M{"$regex": primitive.Regex{Pattern: `^B\.1`}},
+			},
+		},
+		{
+			name:   "seat number and prefix together",
+			filter: models.TicketFilter{SessionID: &sessionHex, SeatNumber: &seat, SeatPrefix: &prefix},
+			want: bson.M{
+				"session_id": sessionID,
+				"seat_number": bson.M{
+					"$eq":    seat,
+					"$regex": primitive.Regex{Pattern: `^B\.1`},
+				},
+			},
+		},
+		{
+			name: "status and statuses together",
+			filter: models.TicketFilter{
+				Status:   models.TicketStatusPaid.Ptr(),
+				Statuses: []models.TicketStatus{models.TicketStatusPaid, models.TicketStatusUsed},
+			},
+			want: bson.M{"status": bson.M{
+				"$eq": models.TicketStatusPaid,
+				"$in": []models.TicketStatus{models.TicketStatusPaid, models.TicketStatusUsed},
+			}},
+		},
+		{
+			name: "closed and open ended time ranges",
+			filter: models.TicketFilter{
+				CreatedFrom:   &from,
+				CreatedTo:     &to,
+				PurchasedFrom: &from,
+			},
+			want: bson.M{
+				"created_at":    bson.M{"$gte": from, "$lte": to},
+				"purchase_time": bson.M{"$gte": from},
+			},
+		},
+		{
+			name:   "price range pins the currency",
+			filter: models.TicketFilter{MinPrice: &low, MaxPrice: &high, PaymentMethod: &method},
+			want: bson.M{
+				"payment_method": method,
+				"price.currency": "KZT",
+				"price.amount":   bson.M{"$gte": low.Amount, "$lte": high.Amount},
+			},
+		},
+		{
+			name:   "max price only",
+			filter: models.TicketFilter{MaxPrice: &high},
+			want: bson.M{
+				"price.currency": "KZT",
+				"price.amount":   bson.M{"$lte": high.Amount},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromTicketFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("FromTicketFilter() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromTicketFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromTicketFilterInvalidID(t *testing.T) {
+	bad := "not-an-object-id"
+
+	if _, err := FromTicketFilter(models.TicketFilter{SessionID: &bad}); err == nil {
+		t.Errorf("FromTicketFilter() accepted session ID %q", bad)
+	}
+}
@@ -0,0 +1,150 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Payment struct {
+	ID             primitive.ObjectID   `bson:"_id,omitempty"`
+	TicketIDs      []primitive.ObjectID `bson:"ticket_ids"`
+	Amount         Money                `bson:"amount"`
+	RefundedAmount Money                `bson:"refunded_amount"`
+	Method         string               `bson:"method"`
+	ProviderRef    string               `bson:"provider_ref,omitempty"`
+	Status         string               `bson:"status"`
+	FailureReason  string               `bson:"failure_reason,omitempty"`
+	ClaimKey       string               `bson:"claim_key,omitempty"`
+	ClaimedAt      time.Time            `bson:"claimed_at,omitempty"`
+	CreatedAt      time.Time            `bson:"created_at"`
+	UpdatedAt      time.Time            `bson:"updated_at"`
+}
+
+func FromPaymentModel(payment models.Payment) (Payment, error) {
+	var objID primitive.ObjectID
+	var err error
+
+	if payment.ID != "" {
+		objID, err = primitive.ObjectIDFromHex(payment.ID)
+		if err != nil {
+			return Payment{}, err
+		}
+	}
+
+	ticketIDs := make([]primitive.ObjectID, len(payment.TicketIDs))
+	for i, id := range payment.TicketIDs {
+		ticketIDs[i], err = primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return Payment{}, err
+		}
+	}
+
+	return Payment{
+		ID:             objID,
+		TicketIDs:      ticketIDs,
+		Amount:         FromMoneyModel(payment.Amount),
+		RefundedAmount: FromMoneyModel(payment.RefundedAmount),
+		Method:         payment.Method,
+		ProviderRef:    payment.ProviderRef,
+		Status:         string(payment.Status),
+		FailureReason:  payment.FailureReason,
+		ClaimKey:       payment.ClaimKey,
+		ClaimedAt:      payment.ClaimedAt,
+		CreatedAt:      payment.CreatedAt,
+		UpdatedAt:      payment.UpdatedAt,
+	}, nil
+}
+
+func ToPaymentModel(payment Payment) models.Payment {
+	ticketIDs := make([]string, len(payment.TicketIDs))
+	for i, id := range payment.TicketIDs {
+		ticketIDs[i] = id.Hex()
+	}
+
+	return models.Payment{
+		ID:             payment.ID.Hex(),
+		TicketIDs:      ticketIDs,
+		Amount:         ToMoneyModel(payment.Amount),
+		RefundedAmount: ToMoneyModel(payment.RefundedAmount),
+		Method:         payment.Method,
+		ProviderRef:    payment.ProviderRef,
+		Status:         models.PaymentStatus(payment.Status),
+		FailureReason:  payment.FailureReason,
+		ClaimKey:       payment.ClaimKey,
+		ClaimedAt:      payment.ClaimedAt,
+		CreatedAt:      payment.CreatedAt,
+		UpdatedAt:      payment.UpdatedAt,
+	}
+}
+
+func FromPaymentFilter(filter models.PaymentFilter) (bson.M, error) {
+	query := bson.M{}
+
+	if filter.ID != nil {
+		objID, err := primitive.ObjectIDFromHex(*filter.ID)
+		if err != nil {
+			return query, err
+		}
+		query["_id"] = objID
+	}
+
+	if filter.TicketID != nil {
+		ticketID, err := primitive.ObjectIDFromHex(*filter.TicketID)
+		if err != nil {
+			return query, err
+		}
+		query["ticket_ids"] = ticketID
+	}
+
+	if filter.ProviderRef != nil {
+		query["provider_ref"] = *filter.ProviderRef
+	}
+
+	if filter.Status != nil {
+		query["status"] = *filter.Status
+	}
+
+	if filter.ClaimKey != nil {
+		query["claim_key"] = *filter.ClaimKey
+	}
+
+	if filter.ClaimedAt != nil {
+		if filter.ClaimedAt.IsZero() {
+			query["claimed_at"] = bson.M{"$exists": false}
+		} else {
+			query["claimed_at"] = *filter.ClaimedAt
+		}
+	}
+
+	return query, nil
+}
+
+func FromPaymentUpdateData(update models.PaymentUpdateData) bson.M {
+	query := bson.M{}
+
+	if update.Status != nil {
+		query["status"] = *update.Status
+	}
+
+	if update.ProviderRef != nil {
+		query["provider_ref"] = *update.ProviderRef
+	}
+
+	if update.RefundedAmount != nil {
+		query["refunded_amount"] = FromMoneyModel(*update.RefundedAmount)
+	}
+
+	if update.FailureReason != nil {
+		query["failure_reason"] = *update.FailureReason
+	}
+
+	if update.ClaimedAt != nil {
+		query["claimed_at"] = *update.ClaimedAt
+	}
+
+	query["updated_at"] = time.Now()
+
+	return bson.M{"$set": query}
+}
@@ -0,0 +1,45 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromPaymentFilterClaim(t *testing.T) {
+	key := "confirm:665f1c2e8b3e4a0012345678:3"
+	claimedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	legacy := time.Time{}
+
+	tests := []struct {
+		name   string
+		filter models.PaymentFilter
+		want   bson.M
+	}{
+		{
+			name:   "claim time",
+			filter: models.PaymentFilter{ClaimKey: &key, ClaimedAt: &claimedAt},
+			want:   bson.M{"claim_key": key, "claimed_at": claimedAt},
+		},
+		{
+			name:   "claim recorded without a time",
+			filter: models.PaymentFilter{ClaimKey: &key, ClaimedAt: &legacy},
+			want:   bson.M{"claim_key": key, "claimed_at": bson.M{"$exists": false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromPaymentFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("FromPaymentFilter() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromPaymentFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type PromoCode struct {
+	ID             primitive.ObjectID   `bson:"_id,omitempty"`
+	Code           string               `bson:"code"`
+	DiscountType   string               `bson:"discount_type"`
+	PercentOff     int64                `bson:"percent_off,omitempty"`
+	AmountOff      *Money               `bson:"amount_off,omitempty"`
+	ValidFrom      time.Time            `bson:"valid_from,omitempty"`
+	ValidUntil     time.Time            `bson:"valid_until,omitempty"`
+	MaxRedemptions int64                `bson:"max_redemptions"`
+	MaxPerUser     int64                `bson:"max_per_user"`
+	Redemptions    int64                `bson:"redemptions"`
+	MovieIDs       []primitive.ObjectID `bson:"movie_ids,omitempty"`
+	SessionIDs     []primitive.ObjectID `bson:"session_ids,omitempty"`
+	CreatedAt      time.Time            `bson:"created_at"`
+}
+
+// PromoRedemption counts one user's uses of a promo code and the tickets
+// they were made for.
+type PromoRedemption struct {
+	ID        primitive.ObjectID   `bson:"_id,omitempty"`
+	CodeID    primitive.ObjectID   `bson:"code_id"`
+	UserID    primitive.ObjectID   `bson:"user_id"`
+	Code      string               `bson:"code"`
+	Count     int64                `bson:"count"`
+	TicketIDs []primitive.ObjectID `bson:"ticket_ids"`
+	CreatedAt time.Time            `bson:"created_at"`
+	UpdatedAt time.Time            `bson:"updated_at"`
+}
+
+func FromPromoCodeModel(promo models.PromoCode) (PromoCode, error) {
+	var objID primitive.ObjectID
+	var err error
+
+	if promo.ID != "" {
+		objID, err = primitive.ObjectIDFromHex(promo.ID)
+		if err != nil {
+			return PromoCode{}, err
+		}
+	}
+
+	movieIDs, err := objectIDsFromHex(promo.MovieIDs)
+	if err != nil {
+		return PromoCode{}, err
+	}
+
+	sessionIDs, err := objectIDsFromHex(promo.SessionIDs)
+	if err != nil {
+		return PromoCode{}, err
+	}
+
+	var amountOff *Money
+	if promo.DiscountType == models.DiscountTypeFixed {
+		amountOff = fromMoneyModelPtr(&promo.AmountOff)
+	}
+
+	return PromoCode{
+		ID:             objID,
+		Code:           promo.Code,
+		DiscountType:   string(promo.DiscountType),
+		PercentOff:     promo.PercentOff,
+		AmountOff:      amountOff,
+		ValidFrom:      promo.ValidFrom,
+		ValidUntil:     promo.ValidUntil,
+		MaxRedemptions: promo.MaxRedemptions,
+		MaxPerUser:     promo.MaxPerUser,
+		Redemptions:    promo.Redemptions,
+		MovieIDs:       movieIDs,
+		SessionIDs:     sessionIDs,
+		CreatedAt:      promo.CreatedAt,
+	}, nil
+}
+
+func ToPromoCodeModel(promo PromoCode) models.PromoCode {
+	var amountOff models.Money
+	if promo.AmountOff != nil {
+		amountOff = ToMoneyModel(*promo.AmountOff)
+	}
+
+	return models.PromoCode{
+		ID:             promo.ID.Hex(),
+		Code:           promo.Code,
+		DiscountType:   models.DiscountType(promo.DiscountType),
+		PercentOff:     promo.PercentOff,
+		AmountOff:      amountOff,
+		ValidFrom:      promo.ValidFrom,
+		ValidUntil:     promo.ValidUntil,
+		MaxRedemptions: promo.MaxRedemptions,
+		MaxPerUser:     promo.MaxPerUser,
+		Redemptions:    promo.Redemptions,
+		MovieIDs:       objectIDsToHex(promo.MovieIDs),
+		SessionIDs:     objectIDsToHex(promo.SessionIDs),
+		CreatedAt:      promo.CreatedAt,
+	}
+}
+
+func objectIDsFromHex(ids []string) ([]primitive.ObjectID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	objIDs := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		objIDs[i] = objID
+	}
+
+	return objIDs, nil
+}
+
+func objectIDsToHex(objIDs []primitive.ObjectID) []string {
+	if len(objIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(objIDs))
+	for i, objID := range objIDs {
+		ids[i] = objID.Hex()
+	}
+
+	return ids
+}
@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"sort"
+	"time"
+)
+
+type salesGroup struct {
+	Key      string `bson:"key"`
+	Currency string `bson:"currency"`
+}
+
+type salesBucket struct {
+	Group  salesGroup `bson:"_id"`
+	Count  int64      `bson:"count"`
+	Amount int64      `bson:"amount"`
+}
+
+// SalesReportFacets holds one bucketed total per kind of ticket activity.
+type SalesReportFacets struct {
+	Reserved  []salesBucket `bson:"reserved"`
+	Sold      []salesBucket `bson:"sold"`
+	Refunded  []salesBucket `bson:"refunded"`
+	Exchanged []salesBucket `bson:"exchanged"`
+}
+
+// ToSalesReportRowModels merges the facets into one row per group and
+// currency, ordered by key, then currency.
+func ToSalesReportRowModels(facets SalesReportFacets) []models.SalesReportRow {
+	type totals struct {
+		reserved, paid, revenue, refunded int64
+	}
+
+	byGroup := make(map[salesGroup]*totals)
+	add := func(buckets []salesBucket, apply func(*totals, salesBucket)) {
+		for _, bucket := range buckets {
+			t, ok := byGroup[bucket.Group]
+			if !ok {
+				t = &totals{}
+				byGroup[bucket.Group] = t
+			}
+			apply(t, bucket)
+		}
+	}
+
+	add(facets.Reserved, func(t *totals, b salesBucket) { t.reserved += b.Count })
+	add(facets.Sold, func(t *totals, b salesBucket) { t.paid += b.Count; t.revenue += b.Amount })
+	add(facets.Refunded, func(t *totals, b salesBucket) { t.refunded += b.Amount })
+	add(facets.Exchanged, func(t *totals, b salesBucket) { t.revenue += b.Amount })
+
+	groups := make([]salesGroup, 0, len(byGroup))
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Key != groups[j].Key {
+			return groups[i].Key < groups[j].Key
+		}
+		return groups[i].Currency < groups[j].Currency
+	})
+
+	rows := make([]models.SalesReportRow, len(groups))
+	for i, group := range groups {
+		t := byGroup[group]
+
+		rows[i] = models.SalesReportRow{
+			Key:        group.Key,
+			Reserved:   t.reserved,
+			Paid:       t.paid,
+			Revenue:    models.NewMoney(t.revenue, group.Currency),
+			Refunded:   models.NewMoney(t.refunded, group.Currency),
+			NetRevenue: models.NewMoney(t.revenue-t.refunded, group.Currency),
+		}
+
+		if t.reserved > 0 {
+			rows[i].ConversionRate = float64(t.paid) / float64(t.reserved)
+		}
+	}
+
+	return rows
+}
+
+type occupancyBucket struct {
+	At    time.Time `bson:"_id"`
+	Count int       `bson:"count"`
+}
+
+// OccupancyFacets holds one bucketed count per kind of seat change.
+type OccupancyFacets struct {
+	Reserved     []occupancyBucket `bson:"reserved"`
+	Paid         []occupancyBucket `bson:"paid"`
+	ReleasedHeld []occupancyBucket `bson:"released_held"`
+	ReleasedSold []occupancyBucket `bson:"released_sold"`
+}
+
+// ToOccupancyDeltaModels merges the facets into one delta per bucket, in
+// time order.
+func ToOccupancyDeltaModels(facets OccupancyFacets) []models.OccupancyDelta {
+	byTime := make(map[time.Time]*models.OccupancyDelta)
+	add := func(buckets []occupancyBucket, field func(*models.OccupancyDelta) *int) {
+		for _, bucket := range buckets {
+			at := bucket.At.UTC()
+			delta, ok := byTime[at]
+			if !ok {
+				delta = &models.OccupancyDelta{At: at}
+				byTime[at] = delta
+			}
+			*field(delta) += bucket.Count
+		}
+	}
+
+	add(facets.Reserved, func(d *models.OccupancyDelta) *int { return &d.Reserved })
+	add(facets.Paid, func(d *models.OccupancyDelta) *int { return &d.Paid })
+	add(facets.ReleasedHeld, func(d *models.OccupancyDelta) *int { return &d.ReleasedHeld })
+	add(facets.ReleasedSold, func(d *models.OccupancyDelta) *int { return &d.ReleasedSold })
+
+	deltas := make([]models.OccupancyDelta, 0, len(byTime))
+	for _, delta := range byTime {
+		deltas = append(deltas, *delta)
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].At.Before(deltas[j].At)
+	})
+
+	return deltas
+}
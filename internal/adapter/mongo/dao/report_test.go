@@ -0,0 +1,161 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToSalesReportRowModels(t *testing.T) {
+	bucket := func(key, currency string, count, amount int64) salesBucket {
+		return salesBucket{Group: salesGroup{Key: key, Currency: currency}, Count: count, Amount: amount}
+	}
+	kzt := func(amount int64) models.Money { return models.NewMoney(amount, "KZT") }
+	usd := func(amount int64) models.Money { return models.NewMoney(amount, "USD") }
+
+	tests := []struct {
+		name   string
+		facets SalesReportFacets
+		want   []models.SalesReportRow
+	}{
+		{name: "no activity", facets: SalesReportFacets{}, want: []models.SalesReportRow{}},
+		{
+			name: "facets of a group merge into one row",
+			facets: SalesReportFacets{
+				Reserved: []salesBucket{bucket("2026-03-01", "KZT", 4, 0)},
+				Sold:     []salesBucket{bucket("2026-03-01", "KZT", 3, 7500)},
+				Refunded: []salesBucket{bucket("2026-03-01", "KZT", 1, 2500)},
+			},
+			want: []models.SalesReportRow{{
+				Key:            "2026-03-01",
+				Reserved:       4,
+				Paid:           3,
+				Revenue:        kzt(7500),
+				Refunded:       kzt(2500),
+				NetRevenue:     kzt(5000),
+				ConversionRate: 0.75,
+			}},
+		},
+		{
+			name: "rows are ordered by key",
+			facets: SalesReportFacets{
+				Reserved: []salesBucket{bucket("s2", "KZT", 1, 0), bucket("s1", "KZT", 2, 0)},
+				Sold:     []salesBucket{bucket("s1", "KZT", 1, 2500)},
+			},
+			want: []models.SalesReportRow{
+				{Key: "s1", Reserved: 2, Paid: 1, Revenue: kzt(2500), Refunded: kzt(0), NetRevenue: kzt(2500), ConversionRate: 0.5},
+				{Key: "s2", Reserved: 1, Revenue: kzt(0), Refunded: kzt(0), NetRevenue: kzt(0)},
+			},
+		},
+		{
+			name: "each currency of a group gets its own row",
+			facets: SalesReportFacets{
+				Reserved: []salesBucket{bucket("m1", "USD", 1, 0), bucket("m1", "KZT", 2, 0)},
+				Sold:     []salesBucket{bucket("m1", "KZT", 2, 5000), bucket("m1", "USD", 1, 1200)},
+				Refunded: []salesBucket{bucket("m1", "USD", 1, 1200)},
+			},
+			want: []models.SalesReportRow{
+				{Key: "m1", Reserved: 2, Paid: 2, Revenue: kzt(5000), Refunded: kzt(0), NetRevenue: kzt(5000), ConversionRate: 1},
+				{Key: "m1", Reserved: 1, Paid: 1, Revenue: usd(1200), Refunded: usd(1200), NetRevenue: usd(0), ConversionRate: 1},
+			},
+		},
+		{
+			name: "sales without reservations in the period have no conversion rate",
+			facets: SalesReportFacets{
+				Sold: []salesBucket{bucket("2026-03-02", "KZT", 2, 5000)},
+			},
+			want: []models.SalesReportRow{
+				{Key: "2026-03-02", Paid: 2, Revenue: kzt(5000), Refunded: kzt(0), NetRevenue: kzt(5000)},
+			},
+		},
+		{
+			name: "refunds alone",
+			facets: SalesReportFacets{
+				Refunded: []salesBucket{bucket("2026-03-03", "KZT", 1, 2500)},
+			},
+			want: []models.SalesReportRow{
+				{Key: "2026-03-03", Revenue: kzt(0), Refunded: kzt(2500), NetRevenue: kzt(-2500)},
+			},
+		},
+		{
+			name: "exchange differences adjust revenue without counting as sales",
+			facets: SalesReportFacets{
+				Sold:      []salesBucket{bucket("s1", "KZT", 1, 2500)},
+				Exchanged: []salesBucket{bucket("s2", "KZT", 0, 1000), bucket("s3", "KZT", 0, -500)},
+			},
+			want: []models.SalesReportRow{
+				{Key: "s1", Paid: 1, Revenue: kzt(2500), Refunded: kzt(0), NetRevenue: kzt(2500)},
+				{Key: "s2", Revenue: kzt(1000), Refunded: kzt(0), NetRevenue: kzt(1000)},
+				{Key: "s3", Revenue: kzt(-500), Refunded: kzt(0), NetRevenue: kzt(-500)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToSalesReportRowModels(tt.facets)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToSalesReportRowModels() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOccupancyDeltaModels(t *testing.T) {
+	hour := func(h int) time.Time { return time.Date(2026, 3, 1, h, 0, 0, 0, time.UTC) }
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+
+	tests := []struct {
+		name   string
+		facets OccupancyFacets
+		want   []models.OccupancyDelta
+	}{
+		{name: "no activity", facets: OccupancyFacets{}, want: []models.OccupancyDelta{}},
+		{
+			name: "reserved and paid in different buckets",
+			facets: OccupancyFacets{
+				Reserved: []occupancyBucket{{At: hour(9), Count: 3}},
+				Paid:     []occupancyBucket{{At: hour(10), Count: 2}},
+			},
+			want: []models.OccupancyDelta{
+				{At: hour(9), Reserved: 3},
+				{At: hour(10), Paid: 2},
+			},
+		},
+		{
+			name: "facets of a bucket merge in time order",
+			facets: OccupancyFacets{
+				Reserved:     []occupancyBucket{{At: hour(11), Count: 1}, {At: hour(9), Count: 4}},
+				Paid:         []occupancyBucket{{At: hour(11), Count: 2}},
+				ReleasedHeld: []occupancyBucket{{At: hour(10), Count: 1}, {At: hour(11), Count: 1}},
+				ReleasedSold: []occupancyBucket{{At: hour(12), Count: 1}},
+			},
+			want: []models.OccupancyDelta{
+				{At: hour(9), Reserved: 4},
+				{At: hour(10), ReleasedHeld: 1},
+				{At: hour(11), Reserved: 1, Paid: 2, ReleasedHeld: 1},
+				{At: hour(12), ReleasedSold: 1},
+			},
+		},
+		{
+			name: "the same instant in another zone is one bucket",
+			facets: OccupancyFacets{
+				Reserved: []occupancyBucket{{At: hour(9), Count: 1}},
+				Paid:     []occupancyBucket{{At: hour(9).In(almaty), Count: 1}},
+			},
+			want: []models.OccupancyDelta{{At: hour(9), Reserved: 1, Paid: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToOccupancyDeltaModels(tt.facets)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToOccupancyDeltaModels() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
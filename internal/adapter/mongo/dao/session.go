@@ -0,0 +1,25 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Session struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	MovieID   primitive.ObjectID `bson:"movie_id"`
+	HallID    primitive.ObjectID `bson:"hall_id"`
+	StartsAt  time.Time          `bson:"starts_at"`
+	BasePrice *Money             `bson:"base_price,omitempty"`
+}
+
+func ToSessionModel(session Session) models.Session {
+	return models.Session{
+		ID:        session.ID.Hex(),
+		MovieID:   session.MovieID.Hex(),
+		HallID:    session.HallID.Hex(),
+		StartsAt:  session.StartsAt,
+		BasePrice: toMoneyModelPtr(session.BasePrice),
+	}
+}
@@ -0,0 +1,55 @@
+package dao
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type TicketTransfer struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	TicketID   primitive.ObjectID `bson:"ticket_id"`
+	FromUserID primitive.ObjectID `bson:"from_user_id"`
+	ToUserID   primitive.ObjectID `bson:"to_user_id"`
+	Status     string             `bson:"status"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+}
+
+func FromTicketTransferModel(transfer models.TicketTransfer) (TicketTransfer, error) {
+	ticketID, err := primitive.ObjectIDFromHex(transfer.TicketID)
+	if err != nil {
+		return TicketTransfer{}, err
+	}
+
+	fromUserID, err := primitive.ObjectIDFromHex(transfer.FromUserID)
+	if err != nil {
+		return TicketTransfer{}, err
+	}
+
+	toUserID, err := primitive.ObjectIDFromHex(transfer.ToUserID)
+	if err != nil {
+		return TicketTransfer{}, err
+	}
+
+	return TicketTransfer{
+		TicketID:   ticketID,
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Status:     string(transfer.Status),
+		CreatedAt:  transfer.CreatedAt,
+		UpdatedAt:  transfer.UpdatedAt,
+	}, nil
+}
+
+func ToTicketTransferModel(transfer TicketTransfer) models.TicketTransfer {
+	return models.TicketTransfer{
+		ID:         transfer.ID.Hex(),
+		TicketID:   transfer.TicketID.Hex(),
+		FromUserID: transfer.FromUserID.Hex(),
+		ToUserID:   transfer.ToUserID.Hex(),
+		Status:     models.TransferStatus(transfer.Status),
+		CreatedAt:  transfer.CreatedAt,
+		UpdatedAt:  transfer.UpdatedAt,
+	}
+}
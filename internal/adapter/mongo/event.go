@@ -0,0 +1,85 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/adapter/mongo/dao"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const collectionTicketEvents = "ticket_events"
+
+type TicketEvent struct {
+	col *mongo.Collection
+}
+
+func NewTicketEvent(conn *mongo.Database) *TicketEvent {
+	collection := conn.Collection(collectionTicketEvents)
+
+	return &TicketEvent{col: collection}
+}
+
+func (db *TicketEvent) EnsureIndexes(ctx context.Context) error {
+	_, err := db.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "ticket_id", Value: 1},
+			{Key: "created_at", Value: 1},
+		},
+	})
+	if err != nil {
+		return mongoError("Indexes.CreateOne", err)
+	}
+
+	return nil
+}
+
+func (db *TicketEvent) InsertMany(ctx context.Context, events []models.TicketEvent) error {
+	daoModels := make([]interface{}, 0, len(events))
+
+	for _, event := range events {
+		daoModel, err := dao.FromTicketEventModel(event)
+		if err != nil {
+			return mongoError("primitive.ObjectIDFromHex", err)
+		}
+		daoModels = append(daoModels, daoModel)
+	}
+
+	if _, err := db.col.InsertMany(ctx, daoModels); err != nil {
+		return mongoError("InsertMany", err)
+	}
+
+	return nil
+}
+
+func (db *TicketEvent) FindByTicket(ctx context.Context, ticketID string) ([]models.TicketEvent, error) {
+	var eventDaos []dao.TicketEvent
+
+	objID, err := primitive.ObjectIDFromHex(ticketID)
+	if err != nil {
+		return nil, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "created_at", Value: 1},
+		{Key: "_id", Value: 1},
+	})
+
+	cur, err := db.col.Find(ctx, bson.M{"ticket_id": objID}, opts)
+	if err != nil {
+		return nil, mongoError("Find", err)
+	}
+
+	if err = cur.All(ctx, &eventDaos); err != nil {
+		return nil, mongoError("Cursor.All", err)
+	}
+
+	events := make([]models.TicketEvent, len(eventDaos))
+	for i := range eventDaos {
+		events[i] = dao.ToTicketEventModel(eventDaos[i])
+	}
+
+	return events, nil
+}
@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/adapter/mongo/dao"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const collectionHalls = "halls"
+
+type Hall struct {
+	col *mongo.Collection
+}
+
+func NewHall(conn *mongo.Database) *Hall {
+	collection := conn.Collection(collectionHalls)
+
+	return &Hall{col: collection}
+}
+
+func (db *Hall) FindBySession(ctx context.Context, sessionID string) (models.Hall, error) {
+	var hallDao dao.Hall
+
+	objID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return models.Hall{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	err = db.col.FindOne(ctx, bson.M{"session_ids": objID}).Decode(&hallDao)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Hall{}, models.ErrHallNotFound
+		}
+
+		return models.Hall{}, mongoError("FindOne", err)
+	}
+
+	return dao.ToHallModel(hallDao), nil
+}
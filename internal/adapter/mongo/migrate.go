@@ -0,0 +1,135 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// moneyFromDouble builds an update-pipeline expression turning the legacy
+// float field into a {amount, currency} document in minor units.
+func moneyFromDouble(field string, currency any, factor int64) bson.M {
+	return bson.M{
+		"amount": bson.M{
+			"$toLong": bson.M{
+				"$round": bson.A{bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$" + field, 0}}, factor}}, 0},
+			},
+		},
+		"currency": currency,
+	}
+}
+
+// MigrateFloatPrices rewrites tickets whose price or refund amount is still
+// stored as a float in major units. Documents already converted are left
+// alone, so it is safe to run on every start.
+func (db *Ticket) MigrateFloatPrices(ctx context.Context, currency string) (int64, error) {
+	factor := models.MinorUnitFactor(currency)
+
+	var migrated int64
+	for _, field := range []string{"price", "refund_amount"} {
+		res, err := db.col.UpdateMany(ctx,
+			bson.M{field: bson.M{"$type": "double"}},
+			bson.A{bson.M{"$set": bson.M{field: moneyFromDouble(field, currency, factor)}}},
+		)
+		if err != nil {
+			return migrated, mongoError("UpdateMany", err)
+		}
+
+		migrated += res.ModifiedCount
+	}
+
+	return migrated, nil
+}
+
+// MigrateFloatAmounts does the same for payment records, folding the
+// separate currency field into the amounts.
+func (db *Payment) MigrateFloatAmounts(ctx context.Context, currency string) (int64, error) {
+	factor := models.MinorUnitFactor(currency)
+	recordCurrency := bson.M{"$ifNull": bson.A{"$currency", currency}}
+
+	res, err := db.col.UpdateMany(ctx,
+		bson.M{"amount": bson.M{"$type": "double"}},
+		bson.A{
+			bson.M{"$set": bson.M{
+				"amount":          moneyFromDouble("amount", recordCurrency, factor),
+				"refunded_amount": moneyFromDouble("refunded_amount", recordCurrency, factor),
+			}},
+			bson.M{"$unset": "currency"},
+		},
+	)
+	if err != nil {
+		return 0, mongoError("UpdateMany", err)
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// MigrateRefundTimes sets refunded_at on refunded tickets written before
+// it was recorded. It is safe to run on every start.
+func (db *Ticket) MigrateRefundTimes(ctx context.Context) (int64, error) {
+	return db.migrateTransitionTimes(ctx, "refunded_at", models.TicketStatusRefunded)
+}
+
+// MigrateReleaseTimes sets released_at on cancelled and expired tickets
+// written before it was recorded. It is safe to run on every start.
+func (db *Ticket) MigrateReleaseTimes(ctx context.Context) (int64, error) {
+	return db.migrateTransitionTimes(ctx, "released_at", models.TicketStatusCancelled, models.TicketStatusExpired)
+}
+
+// migrateTransitionTimes fills field on tickets in one of statuses that
+// lack it with the time of the event that moved the ticket to its status,
+// or its last update when the change predates the event log.
+func (db *Ticket) migrateTransitionTimes(ctx context.Context, field string, statuses ...models.TicketStatus) (int64, error) {
+	missing := bson.M{
+		"status": bson.M{"$in": statuses},
+		field:    bson.M{"$exists": false},
+	}
+
+	count, err := db.col.CountDocuments(ctx, missing)
+	if err != nil {
+		return 0, mongoError("CountDocuments", err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: missing}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": collectionTicketEvents,
+			"let":  bson.M{"ticket_id": "$_id", "status": "$status"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$ticket_id", "$$ticket_id"}},
+					bson.M{"$eq": bson.A{"$new_status", "$$status"}},
+				}}}},
+				bson.M{"$sort": bson.M{"created_at": 1}},
+				bson.M{"$limit": 1},
+			},
+			"as": "transition",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			field: bson.M{"$ifNull": bson.A{
+				bson.M{"$arrayElemAt": bson.A{"$transition.created_at", 0}},
+				"$updated_at",
+			}},
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           collectionTickets,
+			"on":             "_id",
+			"whenMatched":    "merge",
+			"whenNotMatched": "discard",
+		}}},
+	}
+
+	cur, err := db.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, mongoError("Aggregate", err)
+	}
+	if err := cur.Close(ctx); err != nil {
+		return 0, mongoError("Cursor.Close", err)
+	}
+
+	return count, nil
+}
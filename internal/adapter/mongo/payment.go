@@ -0,0 +1,137 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/adapter/mongo/dao"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const collectionPayments = "payments"
+
+type Payment struct {
+	col *mongo.Collection
+}
+
+func NewPayment(conn *mongo.Database) *Payment {
+	collection := conn.Collection(collectionPayments)
+
+	return &Payment{col: collection}
+}
+
+// EnsureIndexes creates the payment indexes. claim_key is unique among
+// pending and succeeded payments so that only one charge per ticket
+// version can be in flight or kept; failed and refunded ones free it.
+func (db *Payment) EnsureIndexes(ctx context.Context) error {
+	_, err := db.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "ticket_ids", Value: 1}}},
+		{Keys: bson.D{{Key: "provider_ref", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "claim_key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"claim_key": bson.M{"$exists": true},
+				"status": bson.M{"$in": bson.A{
+					string(models.PaymentStatusPending),
+					string(models.PaymentStatusSucceeded),
+				}},
+			}),
+		},
+	})
+	if err != nil {
+		return mongoError("Indexes.CreateMany", err)
+	}
+
+	return nil
+}
+
+func (db *Payment) InsertOne(ctx context.Context, payment models.Payment) (models.Payment, error) {
+	paymentDao, err := dao.FromPaymentModel(payment)
+	if err != nil {
+		return models.Payment{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	res, err := db.col.InsertOne(ctx, paymentDao)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.Payment{}, models.ErrPaymentInProgress
+		}
+		return models.Payment{}, mongoError("InsertOne", err)
+	}
+
+	id := res.InsertedID.(primitive.ObjectID).Hex()
+
+	return db.FindOne(ctx, models.PaymentFilter{ID: &id})
+}
+
+func (db *Payment) FindOne(ctx context.Context, filter models.PaymentFilter) (models.Payment, error) {
+	var paymentDao dao.Payment
+
+	query, err := dao.FromPaymentFilter(filter)
+	if err != nil {
+		return models.Payment{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	err = db.col.FindOne(ctx, query).Decode(&paymentDao)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Payment{}, models.ErrPaymentNotFound
+		}
+
+		return models.Payment{}, mongoError("FindOne", err)
+	}
+
+	return dao.ToPaymentModel(paymentDao), nil
+}
+
+func (db *Payment) Find(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error) {
+	var paymentDaos []dao.Payment
+
+	query, err := dao.FromPaymentFilter(filter)
+	if err != nil {
+		return []models.Payment{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cur, err := db.col.Find(ctx, query, opts)
+	if err != nil {
+		return []models.Payment{}, mongoError("Find", err)
+	}
+
+	if err = cur.All(ctx, &paymentDaos); err != nil {
+		return []models.Payment{}, mongoError("Cursor.All", err)
+	}
+
+	payments := make([]models.Payment, len(paymentDaos))
+	for i := range paymentDaos {
+		payments[i] = dao.ToPaymentModel(paymentDaos[i])
+	}
+
+	return payments, nil
+}
+
+func (db *Payment) UpdateOne(ctx context.Context, filter models.PaymentFilter, update models.PaymentUpdateData) (models.Payment, error) {
+	var paymentDao dao.Payment
+
+	query, err := dao.FromPaymentFilter(filter)
+	if err != nil {
+		return models.Payment{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = db.col.FindOneAndUpdate(ctx, query, dao.FromPaymentUpdateData(update), opts).Decode(&paymentDao)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Payment{}, models.ErrPaymentNotFound
+		}
+
+		return models.Payment{}, mongoError("FindOneAndUpdate", err)
+	}
+
+	return dao.ToPaymentModel(paymentDao), nil
+}
@@ -0,0 +1,202 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/adapter/mongo/dao"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const (
+	collectionPromoCodes       = "promo_codes"
+	collectionPromoRedemptions = "promo_redemptions"
+)
+
+// PromoCode stores codes in promo_codes, each with a running redemption
+// count, and per-user usage in promo_redemptions, one document per code
+// and user.
+type PromoCode struct {
+	codes       *mongo.Collection
+	redemptions *mongo.Collection
+}
+
+func NewPromoCode(conn *mongo.Database) *PromoCode {
+	return &PromoCode{
+		codes:       conn.Collection(collectionPromoCodes),
+		redemptions: conn.Collection(collectionPromoRedemptions),
+	}
+}
+
+func (db *PromoCode) EnsureIndexes(ctx context.Context) error {
+	_, err := db.codes.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetName("uniq_code").SetUnique(true),
+	})
+	if err != nil {
+		return mongoError("Indexes.CreateOne", err)
+	}
+
+	_, err = db.redemptions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "code_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_code_user").SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "ticket_ids", Value: 1}}},
+	})
+	if err != nil {
+		return mongoError("Indexes.CreateOne", err)
+	}
+
+	return nil
+}
+
+func (db *PromoCode) InsertOne(ctx context.Context, promo models.PromoCode) (models.PromoCode, error) {
+	promoDao, err := dao.FromPromoCodeModel(promo)
+	if err != nil {
+		return models.PromoCode{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	res, err := db.codes.InsertOne(ctx, promoDao)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.PromoCode{}, models.ErrPromoCodeAlreadyExists
+		}
+
+		return models.PromoCode{}, mongoError("InsertOne", err)
+	}
+
+	promoDao.ID = res.InsertedID.(primitive.ObjectID)
+
+	return dao.ToPromoCodeModel(promoDao), nil
+}
+
+func (db *PromoCode) FindByCode(ctx context.Context, code string) (models.PromoCode, error) {
+	var promoDao dao.PromoCode
+
+	err := db.codes.FindOne(ctx, bson.M{"code": code}).Decode(&promoDao)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.PromoCode{}, models.ErrPromoCodeNotFound
+		}
+
+		return models.PromoCode{}, mongoError("FindOne", err)
+	}
+
+	return dao.ToPromoCodeModel(promoDao), nil
+}
+
+// Redeem uses promo once per ticket in ticketIDs on behalf of userID. Both
+// limits are checked by the same writes that bump the counters, so they
+// hold under concurrent redemptions. Run it in the transaction that creates
+// the tickets: if the per-user limit rejects the redemption, the code's
+// counter increment is rolled back with it.
+func (db *PromoCode) Redeem(ctx context.Context, promo models.PromoCode, userID string, ticketIDs []string) error {
+	uses := int64(len(ticketIDs))
+
+	codeID, err := primitive.ObjectIDFromHex(promo.ID)
+	if err != nil {
+		return mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	ticketObjIDs := make([]primitive.ObjectID, len(ticketIDs))
+	for i, id := range ticketIDs {
+		ticketObjIDs[i], err = primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return mongoError("primitive.ObjectIDFromHex", err)
+		}
+	}
+
+	codeFilter := bson.M{"_id": codeID}
+	if promo.MaxRedemptions > 0 {
+		codeFilter["redemptions"] = bson.M{"$lte": promo.MaxRedemptions - uses}
+	}
+
+	res, err := db.codes.UpdateOne(ctx, codeFilter, bson.M{"$inc": bson.M{"redemptions": uses}})
+	if err != nil {
+		return mongoError("UpdateOne", err)
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrPromoCodeExhausted
+	}
+
+	userFilter := bson.M{"code_id": codeID, "user_id": userObjID}
+	if promo.MaxPerUser > 0 {
+		if uses > promo.MaxPerUser {
+			return models.ErrPromoCodeUserLimit
+		}
+		userFilter["count"] = bson.M{"$lte": promo.MaxPerUser - uses}
+	}
+
+	now := time.Now()
+
+	// Once the user is at the limit the filter stops matching and the
+	// upsert collides with their existing document on the unique index.
+	_, err = db.redemptions.UpdateOne(ctx, userFilter, bson.M{
+		"$inc":         bson.M{"count": uses},
+		"$push":        bson.M{"ticket_ids": bson.M{"$each": ticketObjIDs}},
+		"$set":         bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{"code": promo.Code, "created_at": now},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.ErrPromoCodeUserLimit
+		}
+
+		return mongoError("UpdateOne", err)
+	}
+
+	return nil
+}
+
+// Release gives back the redemption of code made for ticketID, on both the
+// code's counter and the redeeming user's count. The ticket is pulled from
+// the user's redemption in the same write that lowers its count, so
+// releasing twice has no further effect. Run it in the transaction that
+// moves the ticket out of use.
+func (db *PromoCode) Release(ctx context.Context, code, ticketID string) error {
+	ticketObjID, err := primitive.ObjectIDFromHex(ticketID)
+	if err != nil {
+		return mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	var redemption dao.PromoRedemption
+
+	err = db.redemptions.FindOneAndUpdate(ctx,
+		bson.M{"code": code, "ticket_ids": ticketObjID},
+		bson.M{
+			"$inc":  bson.M{"count": -1},
+			"$pull": bson.M{"ticket_ids": ticketObjID},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	).Decode(&redemption)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+
+		return mongoError("FindOneAndUpdate", err)
+	}
+
+	_, err = db.codes.UpdateOne(ctx,
+		bson.M{"_id": redemption.CodeID, "redemptions": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"redemptions": -1}},
+	)
+	if err != nil {
+		return mongoError("UpdateOne", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/adapter/mongo/dao"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const collectionSessions = "sessions"
+
+type Session struct {
+	col *mongo.Collection
+}
+
+func NewSession(conn *mongo.Database) *Session {
+	collection := conn.Collection(collectionSessions)
+
+	return &Session{col: collection}
+}
+
+func (db *Session) FindByID(ctx context.Context, sessionID string) (models.Session, error) {
+	var sessionDao dao.Session
+
+	objID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return models.Session{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	err = db.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&sessionDao)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Session{}, models.ErrSessionNotFound
+		}
+
+		return models.Session{}, mongoError("FindOne", err)
+	}
+
+	return dao.ToSessionModel(sessionDao), nil
+}
@@ -8,10 +8,14 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const collectionTickets = "tickets"
 
+// findBatchSize caps how many tickets a cursor holds in memory at once.
+const findBatchSize = 500
+
 type Ticket struct {
 	col *mongo.Collection
 }
@@ -22,6 +26,36 @@ func NewTicket(conn *mongo.Database) *Ticket {
 	return &Ticket{col: collection}
 }
 
+// EnsureIndexes creates the indexes the tickets collection relies on.
+// The partial unique index on session_id + seat_number guarantees a seat
+// can be held by at most one active (RESERVED or PAID) ticket, regardless
+// of how many replicas race to insert it.
+func (db *Ticket) EnsureIndexes(ctx context.Context) error {
+	seatIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "session_id", Value: 1},
+			{Key: "seat_number", Value: 1},
+		},
+		Options: options.Index().
+			SetName("uniq_active_seat").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{
+				"status": bson.M{
+					"$in": []string{
+						string(models.TicketStatusReserved),
+						string(models.TicketStatusPaid),
+					},
+				},
+			}),
+	}
+
+	if _, err := db.col.Indexes().CreateOne(ctx, seatIndex); err != nil {
+		return mongoError("Indexes.CreateOne", err)
+	}
+
+	return nil
+}
+
 func (db *Ticket) InsertOne(ctx context.Context, ticket *models.Ticket) (models.Ticket, error) {
 	available, err := db.IsSeatAvailable(ctx, ticket.SessionID, ticket.SeatNumber)
 	if err != nil {
@@ -39,6 +73,10 @@ func (db *Ticket) InsertOne(ctx context.Context, ticket *models.Ticket) (models.
 	res, err := db.col.InsertOne(ctx, ticketDao)
 
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.Ticket{}, models.ErrSeatAlreadyTaken
+		}
+
 		return models.Ticket{}, mongoError("insertOne", err)
 	}
 
@@ -67,24 +105,24 @@ func (db *Ticket) FindOne(ctx context.Context, filter models.TicketFilter) (mode
 	return dao.ToModel(ticketDao), nil
 }
 
-func (db *Ticket) Find(ctx context.Context, filter models.TicketFilter) ([]models.Ticket, error) {
+// Find returns the tickets matching filter in page's order, at most
+// page.Size of them, and the cursor of the next page if there is one.
+func (db *Ticket) Find(ctx context.Context, filter models.TicketFilter, page models.PageRequest) ([]models.Ticket, string, error) {
 	var ticketDaos []dao.Ticket
-	query, err := dao.FromTicketFilter(filter)
-	if err != nil {
-		return []models.Ticket{}, mongoError("primitive.ObjectIDFromHex", err)
-	}
 
-	cur, err := db.col.Find(ctx, query)
+	cur, err := db.find(ctx, filter, page)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return []models.Ticket{}, models.ErrTicketNotFound
-		}
-
-		return []models.Ticket{}, mongoError("Find", err)
+		return []models.Ticket{}, "", err
 	}
 
 	if err = cur.All(ctx, &ticketDaos); err != nil {
-		return []models.Ticket{}, mongoError("Cursor.All", err)
+		return []models.Ticket{}, "", mongoError("Cursor.All", err)
+	}
+
+	var nextCursor string
+	if page.Size > 0 && len(ticketDaos) > page.Size {
+		ticketDaos = ticketDaos[:page.Size]
+		nextCursor = dao.EncodeTicketCursor(ticketDaos[page.Size-1], page)
 	}
 
 	tickets := make([]models.Ticket, len(ticketDaos))
@@ -93,26 +131,122 @@ func (db *Ticket) Find(ctx context.Context, filter models.TicketFilter) ([]model
 		tickets[i] = dao.ToModel(ticketDaos[i])
 	}
 
-	return tickets, nil
+	return tickets, nextCursor, nil
+}
+
+// Stream walks the tickets matching filter in page's order and hands them
+// to fn one at a time, so the result set never has to fit in memory. The
+// cursor only fetches the next batch once fn has consumed the current one.
+// It stops at the first error from fn or when ctx is done.
+func (db *Ticket) Stream(ctx context.Context, filter models.TicketFilter, page models.PageRequest, fn func(models.Ticket) error) error {
+	cur, err := db.find(ctx, filter, page)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(context.WithoutCancel(ctx))
+
+	for cur.Next(ctx) {
+		var ticketDao dao.Ticket
+		if err := cur.Decode(&ticketDao); err != nil {
+			return mongoError("Cursor.Decode", err)
+		}
+
+		if err := fn(dao.ToModel(ticketDao)); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := cur.Err(); err != nil {
+		return mongoError("Cursor.Next", err)
+	}
+
+	return nil
 }
 
+// find opens a cursor over the tickets matching filter, positioned after
+// page.Cursor and limited to one ticket past page.Size.
+func (db *Ticket) find(ctx context.Context, filter models.TicketFilter, page models.PageRequest) (*mongo.Cursor, error) {
+	query, err := dao.FromTicketFilter(filter)
+	if err != nil {
+		return nil, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	if page.Cursor != "" {
+		after, err := dao.FromTicketCursor(page.Cursor, page)
+		if err != nil {
+			return nil, err
+		}
+		query = bson.M{"$and": bson.A{query, after}}
+	}
+
+	direction := 1
+	if page.Descending {
+		direction = -1
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{
+			{Key: dao.TicketSortKey(page.SortBy), Value: direction},
+			{Key: "_id", Value: direction},
+		}).
+		SetBatchSize(findBatchSize)
+	if page.Size > 0 {
+		// One extra ticket tells whether another page follows.
+		opts.SetLimit(int64(page.Size) + 1)
+	}
+
+	cur, err := db.col.Find(ctx, query, opts)
+	if err != nil {
+		return nil, mongoError("Find", err)
+	}
+
+	return cur, nil
+}
+
+// UpdateOne applies update to the ticket matching filter and returns it as
+// stored afterwards. When filter pins a Version or Status and the ticket
+// exists but no longer matches, it returns models.ErrTicketVersionConflict.
 func (db *Ticket) UpdateOne(ctx context.Context, filter models.TicketFilter, update models.TicketUpdateData) (models.Ticket, error) {
+	var ticketDao dao.Ticket
+
 	query, err := dao.FromTicketFilter(filter)
 
 	if err != nil {
 		return models.Ticket{}, mongoError("primitive.ObjectIDFromHex", err)
 	}
 
-	res, err := db.col.UpdateOne(ctx, query, dao.FromTicketUpdateData(update))
+	updateQuery, err := dao.FromTicketUpdateData(update)
 	if err != nil {
-		return models.Ticket{}, mongoError("UpdateOne", err)
+		return models.Ticket{}, mongoError("primitive.ObjectIDFromHex", err)
 	}
 
-	if res.MatchedCount == 0 {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = db.col.FindOneAndUpdate(ctx, query, updateQuery, opts).Decode(&ticketDao)
+	if err != nil {
+		// Moving a ticket onto a seat another active ticket holds.
+		if mongo.IsDuplicateKeyError(err) {
+			return models.Ticket{}, models.ErrSeatAlreadyTaken
+		}
+
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Ticket{}, mongoError("FindOneAndUpdate", err)
+		}
+
+		if filter.ID != nil && (filter.Version != nil || filter.Status != nil) {
+			if _, findErr := db.FindOne(ctx, models.TicketFilter{ID: filter.ID}); findErr == nil {
+				return models.Ticket{}, models.ErrTicketVersionConflict
+			}
+		}
+
 		return models.Ticket{}, models.ErrTicketNotFound
 	}
 
-	return db.FindOne(ctx, filter)
+	return dao.ToModel(ticketDao), nil
 }
 
 func (db *Ticket) DeleteOne(ctx context.Context, filter models.TicketFilter) (models.Ticket, error) {
@@ -170,9 +304,45 @@ func (db *Ticket) IsSeatAvailable(ctx context.Context, sessionID string, seatNum
 	return count == 0, nil
 }
 
+func (db *Ticket) FindSeatStates(ctx context.Context, sessionID string) (map[string]models.SeatState, error) {
+	objID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	filter := bson.M{
+		"session_id": objID,
+		"status": bson.M{
+			"$in": []string{
+				string(models.TicketStatusReserved),
+				string(models.TicketStatusPaid),
+			},
+		},
+	}
+	opts := options.Find().SetProjection(bson.M{"seat_number": 1, "status": 1})
+
+	cur, err := db.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, mongoError("Find", err)
+	}
+
+	var ticketDaos []dao.Ticket
+	if err = cur.All(ctx, &ticketDaos); err != nil {
+		return nil, mongoError("Cursor.All", err)
+	}
+
+	seats := make(map[string]models.SeatState, len(ticketDaos))
+	for _, ticketDao := range ticketDaos {
+		seats[ticketDao.SeatNumber] = models.SeatStateFromTicketStatus(models.TicketStatus(ticketDao.Status))
+	}
+
+	return seats, nil
+}
+
+// InsertMany inserts tickets together. Seats another active ticket already
+// holds are reported per seat in a models.SeatReservationError.
 func (db *Ticket) InsertMany(ctx context.Context, tickets []models.Ticket) ([]models.Ticket, error) {
-	var daoModels []interface{}
-	ids := make([]string, 0, len(tickets))
+	daoModels := make([]interface{}, 0, len(tickets))
 
 	for _, ticket := range tickets {
 		daoModel, err := dao.FromModel(ticket)
@@ -180,15 +350,49 @@ func (db *Ticket) InsertMany(ctx context.Context, tickets []models.Ticket) ([]mo
 			return nil, mongoError("FromModel", err)
 		}
 		daoModels = append(daoModels, daoModel)
-		ids = append(ids, ticket.ID)
 	}
 
-	_, err := db.col.InsertMany(ctx, daoModels)
+	// Unordered, so a seat lost to a racing reservation does not stop the
+	// rest of the batch from being tried and every lost seat is reported.
+	res, err := db.col.InsertMany(ctx, daoModels, options.InsertMany().SetOrdered(false))
 	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && mongo.IsDuplicateKeyError(err) {
+			return nil, seatConflicts(tickets, bulkErr)
+		}
+
 		return nil, mongoError("InsertMany", err)
 	}
 
-	return db.Find(ctx, models.TicketFilter{IDs: ids})
+	ids := make([]string, 0, len(res.InsertedIDs))
+	for _, id := range res.InsertedIDs {
+		ids = append(ids, id.(primitive.ObjectID).Hex())
+	}
+
+	inserted, _, err := db.Find(ctx, models.TicketFilter{IDs: ids}, models.PageRequest{})
+
+	return inserted, err
+}
+
+// seatConflicts maps duplicate-key write errors back to the seats that caused them.
+func seatConflicts(tickets []models.Ticket, bulkErr mongo.BulkWriteException) error {
+	seatErrs := make(map[string]error)
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index < 0 || writeErr.Index >= len(tickets) {
+			continue
+		}
+
+		if mongo.IsDuplicateKeyError(writeErr) {
+			seatErrs[tickets[writeErr.Index].SeatNumber] = models.ErrSeatAlreadyTaken
+		}
+	}
+
+	if len(seatErrs) == 0 {
+		return models.ErrSeatAlreadyTaken
+	}
+
+	return &models.SeatReservationError{Seats: seatErrs}
 }
 
 func (db *Ticket) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
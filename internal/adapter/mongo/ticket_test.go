@@ -0,0 +1,214 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testMongoURIEnv names the server the repository tests run against, e.g.
+// mongodb://localhost:27017. The tests are skipped when it is unset.
+const testMongoURIEnv = "MONGO_TEST_URI"
+
+// newTestTickets returns a ticket repository over a fresh database with
+// its indexes in place; the database is dropped when the test ends.
+func newTestTickets(t *testing.T) *Ticket {
+	t.Helper()
+
+	uri := os.Getenv(testMongoURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", testMongoURIEnv)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("mongo.Connect() error = %v", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	db := client.Database(fmt.Sprintf("ticket_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_ = db.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+
+	tickets := NewTicket(db)
+	if err := tickets.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes() error = %v", err)
+	}
+
+	return tickets
+}
+
+func newTestTicket(sessionID, seatNumber string) models.Ticket {
+	return models.Ticket{
+		SessionID:  sessionID,
+		MovieID:    primitive.NewObjectID().Hex(),
+		UserID:     primitive.NewObjectID().Hex(),
+		SeatNumber: seatNumber,
+		Price:      models.NewMoney(2500, "KZT"),
+		Status:     models.TicketStatusReserved,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+func TestTicketInsertOneConcurrentSameSeat(t *testing.T) {
+	const callers = 32
+
+	tickets := newTestTickets(t)
+	sessionID := primitive.NewObjectID().Hex()
+
+	start := make(chan struct{})
+	errs := make([]error, callers)
+
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			ticket := newTestTicket(sessionID, "A1")
+			_, errs[i] = tickets.InsertOne(context.Background(), &ticket)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	inserted := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			inserted++
+		case errors.Is(err, models.ErrSeatAlreadyTaken):
+		default:
+			t.Errorf("InsertOne() unexpected error: %v", err)
+		}
+	}
+
+	if inserted != 1 {
+		t.Errorf("%d inserts took the seat, want 1", inserted)
+	}
+
+	held, err := tickets.col.CountDocuments(context.Background(), bson.M{"seat_number": "A1"})
+	if err != nil {
+		t.Fatalf("CountDocuments() error = %v", err)
+	}
+	if held != 1 {
+		t.Errorf("seat is held by %d tickets, want 1", held)
+	}
+}
+
+func TestTicketUniqueActiveSeat(t *testing.T) {
+	tickets := newTestTickets(t)
+	ctx := context.Background()
+	sessionID := primitive.NewObjectID().Hex()
+
+	first := newTestTicket(sessionID, "B1")
+	held, err := tickets.InsertOne(ctx, &first)
+	if err != nil {
+		t.Fatalf("InsertOne() error = %v", err)
+	}
+
+	other := newTestTicket(sessionID, "B2")
+	moving, err := tickets.InsertOne(ctx, &other)
+	if err != nil {
+		t.Fatalf("InsertOne() error = %v", err)
+	}
+
+	// UpdateOne has no availability pre-check, so only the index stands
+	// between a move and a double booking.
+	_, err = tickets.UpdateOne(ctx, models.TicketFilter{ID: &moving.ID}, models.TicketUpdateData{
+		SeatNumber: &held.SeatNumber,
+	})
+	if !errors.Is(err, models.ErrSeatAlreadyTaken) {
+		t.Fatalf("UpdateOne() onto a held seat error = %v, want %v", err, models.ErrSeatAlreadyTaken)
+	}
+
+	// Tickets that no longer hold their seat fall outside the partial index.
+	if _, err := tickets.UpdateOne(ctx, models.TicketFilter{ID: &held.ID}, models.TicketUpdateData{
+		Status: models.TicketStatusCancelled.Ptr(),
+	}); err != nil {
+		t.Fatalf("UpdateOne() cancel error = %v", err)
+	}
+
+	if _, err := tickets.UpdateOne(ctx, models.TicketFilter{ID: &moving.ID}, models.TicketUpdateData{
+		SeatNumber: &held.SeatNumber,
+	}); err != nil {
+		t.Fatalf("UpdateOne() onto a released seat error = %v", err)
+	}
+}
+
+func TestSeatConflicts(t *testing.T) {
+	tickets := []models.Ticket{
+		newTestTicket("s", "C1"),
+		newTestTicket("s", "C2"),
+		newTestTicket("s", "C3"),
+	}
+
+	err := seatConflicts(tickets, mongo.BulkWriteException{
+		WriteErrors: []mongo.BulkWriteError{
+			{WriteError: mongo.WriteError{Index: 0, Code: 11000}},
+			{WriteError: mongo.WriteError{Index: 2, Code: 11000}},
+		},
+	})
+
+	var seatErr *models.SeatReservationError
+	if !errors.As(err, &seatErr) {
+		t.Fatalf("seatConflicts() = %v, want a SeatReservationError", err)
+	}
+
+	if len(seatErr.Seats) != 2 {
+		t.Errorf("seatConflicts() reported %d seats, want 2: %v", len(seatErr.Seats), err)
+	}
+	for _, seat := range []string{"C1", "C3"} {
+		if !errors.Is(seatErr.Seats[seat], models.ErrSeatAlreadyTaken) {
+			t.Errorf("seat %s error = %v, want %v", seat, seatErr.Seats[seat], models.ErrSeatAlreadyTaken)
+		}
+	}
+}
+
+func TestTicketInsertManyReportsEveryTakenSeat(t *testing.T) {
+	tickets := newTestTickets(t)
+	ctx := context.Background()
+	sessionID := primitive.NewObjectID().Hex()
+
+	for _, seat := range []string{"D1", "D3"} {
+		ticket := newTestTicket(sessionID, seat)
+		if _, err := tickets.InsertOne(ctx, &ticket); err != nil {
+			t.Fatalf("InsertOne(%s) error = %v", seat, err)
+		}
+	}
+
+	_, err := tickets.InsertMany(ctx, []models.Ticket{
+		newTestTicket(sessionID, "D1"),
+		newTestTicket(sessionID, "D2"),
+		newTestTicket(sessionID, "D3"),
+	})
+
+	var seatErr *models.SeatReservationError
+	if !errors.As(err, &seatErr) {
+		t.Fatalf("InsertMany() error = %v, want a SeatReservationError", err)
+	}
+	if len(seatErr.Seats) != 2 || seatErr.Seats["D1"] == nil || seatErr.Seats["D3"] == nil {
+		t.Errorf("InsertMany() reported %v, want D1 and D3", err)
+	}
+}
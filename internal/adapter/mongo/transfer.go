@@ -0,0 +1,127 @@
+package mongo
+
+import (
+	"ap2final_ticket_service/internal/adapter/mongo/dao"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const collectionTicketTransfers = "ticket_transfers"
+
+type TicketTransfer struct {
+	col *mongo.Collection
+}
+
+func NewTicketTransfer(conn *mongo.Database) *TicketTransfer {
+	collection := conn.Collection(collectionTicketTransfers)
+
+	return &TicketTransfer{col: collection}
+}
+
+// EnsureIndexes allows at most one pending transfer per ticket.
+func (db *TicketTransfer) EnsureIndexes(ctx context.Context) error {
+	_, err := db.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "ticket_id", Value: 1}},
+		Options: options.Index().
+			SetName("uniq_pending_transfer").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"status": string(models.TransferStatusPending)}),
+	})
+	if err != nil {
+		return mongoError("Indexes.CreateOne", err)
+	}
+
+	return nil
+}
+
+func (db *TicketTransfer) InsertOne(ctx context.Context, transfer models.TicketTransfer) (models.TicketTransfer, error) {
+	transferDao, err := dao.FromTicketTransferModel(transfer)
+	if err != nil {
+		return models.TicketTransfer{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	res, err := db.col.InsertOne(ctx, transferDao)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.TicketTransfer{}, models.ErrTransferAlreadyPending
+		}
+
+		return models.TicketTransfer{}, mongoError("InsertOne", err)
+	}
+
+	transferDao.ID = res.InsertedID.(primitive.ObjectID)
+
+	return dao.ToTicketTransferModel(transferDao), nil
+}
+
+func (db *TicketTransfer) FindByID(ctx context.Context, id string) (models.TicketTransfer, error) {
+	var transferDao dao.TicketTransfer
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.TicketTransfer{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	err = db.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&transferDao)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.TicketTransfer{}, models.ErrTransferNotFound
+		}
+
+		return models.TicketTransfer{}, mongoError("FindOne", err)
+	}
+
+	return dao.ToTicketTransferModel(transferDao), nil
+}
+
+// Resolve moves a pending transfer to status. It fails with
+// models.ErrTransferNotPending if the transfer was resolved meanwhile.
+func (db *TicketTransfer) Resolve(ctx context.Context, id string, status models.TransferStatus) (models.TicketTransfer, error) {
+	var transferDao dao.TicketTransfer
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.TicketTransfer{}, mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = db.col.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "status": string(models.TransferStatusPending)},
+		bson.M{"$set": bson.M{"status": string(status), "updated_at": time.Now()}},
+		opts,
+	).Decode(&transferDao)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.TicketTransfer{}, models.ErrTransferNotPending
+		}
+
+		return models.TicketTransfer{}, mongoError("FindOneAndUpdate", err)
+	}
+
+	return dao.ToTicketTransferModel(transferDao), nil
+}
+
+// CancelPending withdraws any pending transfer of the ticket.
+func (db *TicketTransfer) CancelPending(ctx context.Context, ticketID string) error {
+	objID, err := primitive.ObjectIDFromHex(ticketID)
+	if err != nil {
+		return mongoError("primitive.ObjectIDFromHex", err)
+	}
+
+	_, err = db.col.UpdateMany(ctx,
+		bson.M{"ticket_id": objID, "status": string(models.TransferStatusPending)},
+		bson.M{"$set": bson.M{"status": string(models.TransferStatusCancelled), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return mongoError("UpdateMany", err)
+	}
+
+	return nil
+}
@@ -3,16 +3,23 @@ package app
 import (
 	"ap2final_ticket_service/internal/adapter/cache"
 	grpcserver "ap2final_ticket_service/internal/adapter/grpc"
+	"ap2final_ticket_service/internal/adapter/layout"
 	mongorepo "ap2final_ticket_service/internal/adapter/mongo"
 	"ap2final_ticket_service/internal/config"
+	"ap2final_ticket_service/internal/payment"
+	"ap2final_ticket_service/internal/pricing"
 	"ap2final_ticket_service/internal/usecase"
 	"context"
+	"fmt"
 	"github.com/sorawaslocked/ap2final_base/pkg/logger"
 	mongocfg "github.com/sorawaslocked/ap2final_base/pkg/mongo"
+	"github.com/sorawaslocked/ap2final_base/pkg/security"
+	"go.mongodb.org/mongo-driver/mongo"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 const serviceName = "ticket service"
@@ -20,6 +27,8 @@ const serviceName = "ticket service"
 type App struct {
 	grpcServer *grpcserver.Server
 	cache      *cache.RedisCache
+	stopFake   func()
+	reaper     *reaper
 	log        *slog.Logger
 }
 
@@ -51,19 +60,208 @@ func New(
 
 	ticketRepo := mongorepo.NewTicket(db.Connection)
 
-	ticketUseCase := usecase.NewTicketUseCase(ticketRepo, redisCache, log)
+	eventRepo := mongorepo.NewTicketEvent(db.Connection)
 
-	grpcServer := grpcserver.New(cfg.Server.GRPC, log, ticketUseCase)
+	if err := eventRepo.EnsureIndexes(ctx); err != nil {
+		newLog.Error("error creating ticket event indexes", logger.Err(err))
+		return nil, err
+	}
+
+	paymentRepo := mongorepo.NewPayment(db.Connection)
+
+	if err := paymentRepo.EnsureIndexes(ctx); err != nil {
+		newLog.Error("error creating payment indexes", logger.Err(err))
+		return nil, err
+	}
+
+	promoRepo := mongorepo.NewPromoCode(db.Connection)
+
+	if err := promoRepo.EnsureIndexes(ctx); err != nil {
+		newLog.Error("error creating promo code indexes", logger.Err(err))
+		return nil, err
+	}
+
+	transferRepo := mongorepo.NewTicketTransfer(db.Connection)
+
+	if err := transferRepo.EnsureIndexes(ctx); err != nil {
+		newLog.Error("error creating ticket transfer indexes", logger.Err(err))
+		return nil, err
+	}
+
+	if _, err := time.LoadLocation(cfg.Reports.Timezone); err != nil {
+		newLog.Error("error loading report timezone", logger.Err(err))
+		return nil, err
+	}
+
+	analyticsRepo := mongorepo.NewAnalytics(db.Connection, cfg.Reports.Timezone)
+
+	hallRepo, sessionRepo, err := newLayoutRepositories(cfg.Layout, db.Connection)
+	if err != nil {
+		newLog.Error("error loading hall layouts", logger.Err(err))
+		return nil, err
+	}
+
+	newLog.Info("migrating float prices", slog.String("currency", cfg.Payment.Currency))
+	if err := migrateFloatPrices(ctx, ticketRepo, paymentRepo, cfg.Payment.Currency, newLog); err != nil {
+		newLog.Error("error migrating float prices", logger.Err(err))
+		return nil, err
+	}
+
+	if err := migrateTransitionTimes(ctx, ticketRepo, newLog); err != nil {
+		newLog.Error("error migrating ticket transition times", logger.Err(err))
+		return nil, err
+	}
+
+	newLog.Info("ensuring ticket indexes")
+	if err := ticketRepo.EnsureIndexes(ctx); err != nil {
+		newLog.Error("error creating ticket indexes", logger.Err(err))
+		return nil, err
+	}
+
+	paymentService, stopFake, err := newPaymentService(cfg.Payment, newLog)
+	if err != nil {
+		newLog.Error("error configuring payment provider", logger.Err(err))
+		return nil, err
+	}
+
+	pricingEngine, err := pricing.NewRuleEngine(cfg.Pricing, cfg.Payment.Currency)
+	if err != nil {
+		newLog.Error("error configuring pricing rules", logger.Err(err))
+		return nil, err
+	}
+
+	ticketUseCase := usecase.NewTicketUseCase(
+		ticketRepo,
+		eventRepo,
+		paymentRepo,
+		promoRepo,
+		transferRepo,
+		analyticsRepo,
+		hallRepo,
+		sessionRepo,
+		redisCache,
+		redisCache,
+		paymentService,
+		pricingEngine,
+		cfg.Reservation.HoldDuration,
+		cfg.Payment.HTTP.MaxCallDuration(),
+		cfg.Reports.Capacity,
+		cfg.Layout.AllowUnmappedSessions,
+		log,
+	)
+
+	var verifier grpcserver.TokenVerifier
+	if cfg.Auth.JWTSecret != "" {
+		verifier = security.NewJWTProvider(cfg.Auth.JWTSecret, 0, 0)
+	} else {
+		newLog.Warn("auth.jwtSecret is not set, serving every request anonymously")
+	}
+
+	grpcServer := grpcserver.New(cfg.Server.GRPC, log, ticketUseCase, verifier, cfg.Payment.Currency)
 
 	return &App{
 		grpcServer: grpcServer,
 		cache:      redisCache,
+		stopFake:   stopFake,
+		reaper:     newReaper(ticketUseCase, cfg.Reservation.ReapInterval, log),
 		log:        log,
 	}, nil
 }
 
+// migrateFloatPrices converts prices stored as major-unit floats by earlier
+// releases into exact minor-unit amounts. Converted documents are skipped.
+func migrateFloatPrices(
+	ctx context.Context,
+	tickets *mongorepo.Ticket,
+	payments *mongorepo.Payment,
+	currency string,
+	log *slog.Logger,
+) error {
+	migratedTickets, err := tickets.MigrateFloatPrices(ctx, currency)
+	if err != nil {
+		return err
+	}
+
+	migratedPayments, err := payments.MigrateFloatAmounts(ctx, currency)
+	if err != nil {
+		return err
+	}
+
+	if migratedTickets > 0 || migratedPayments > 0 {
+		log.Info("migrated float prices",
+			slog.Int64("tickets", migratedTickets),
+			slog.Int64("payments", migratedPayments),
+		)
+	}
+
+	return nil
+}
+
+// migrateTransitionTimes records when tickets written by earlier releases
+// were refunded, cancelled or expired, which reports bucket them by.
+func migrateTransitionTimes(ctx context.Context, tickets *mongorepo.Ticket, log *slog.Logger) error {
+	refunded, err := tickets.MigrateRefundTimes(ctx)
+	if err != nil {
+		return err
+	}
+
+	released, err := tickets.MigrateReleaseTimes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if refunded > 0 || released > 0 {
+		log.Info("migrated ticket transition times",
+			slog.Int64("refunded", refunded),
+			slog.Int64("released", released),
+		)
+	}
+
+	return nil
+}
+
+// newLayoutRepositories reads hall layouts and the session schedule from
+// the same source.
+func newLayoutRepositories(
+	cfg config.Layout,
+	conn *mongo.Database,
+) (usecase.HallRepository, usecase.SessionRepository, error) {
+	switch cfg.Source {
+	case "file":
+		file, err := layout.LoadFile(cfg.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return file, file.Schedule(), nil
+	case "mongo":
+		return mongorepo.NewHall(conn), mongorepo.NewSession(conn), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown layout source %q", cfg.Source)
+	}
+}
+
+// newPaymentService builds the configured provider. For the "fake" provider
+// it also returns a func that stops the stand-in gateway on shutdown.
+func newPaymentService(cfg config.Payment, log *slog.Logger) (payment.Service, func(), error) {
+	switch cfg.Provider {
+	case "mock":
+		return payment.NewMockPaymentService(), nil, nil
+	case "http":
+		return payment.NewHTTPPaymentService(cfg.HTTP), nil, nil
+	case "fake":
+		return newFakePaymentService(cfg.HTTP, log)
+	default:
+		return nil, nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
+
 func (a *App) stop() {
 	a.grpcServer.Stop()
+	a.reaper.stop()
+	if a.stopFake != nil {
+		a.stopFake()
+	}
 	if err := a.cache.Close(); err != nil {
 		a.log.Error("error closing redis cache", logger.Err(err))
 	}
@@ -71,6 +269,7 @@ func (a *App) stop() {
 
 func (a *App) Run() {
 	a.grpcServer.MustRun()
+	a.reaper.start()
 
 	shutdownCh := make(chan os.Signal, 1)
 	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
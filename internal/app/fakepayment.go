@@ -0,0 +1,23 @@
+//go:build fakepayment
+
+package app
+
+import (
+	"ap2final_ticket_service/internal/config"
+	"ap2final_ticket_service/internal/payment"
+	"ap2final_ticket_service/internal/payment/paymenttest"
+	"log/slog"
+)
+
+// newFakePaymentService talks HTTP to an in-process stand-in gateway that
+// approves everything. It is only built with the fakepayment tag, so the
+// test gateway never ships in a regular service binary.
+func newFakePaymentService(cfg config.PaymentHTTP, log *slog.Logger) (payment.Service, func(), error) {
+	gateway := paymenttest.NewGateway()
+
+	log.Info("using local stand-in payment gateway", slog.String("url", gateway.URL()))
+
+	cfg.BaseURL = gateway.URL()
+
+	return payment.NewHTTPPaymentService(cfg), gateway.Close, nil
+}
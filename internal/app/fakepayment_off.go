@@ -0,0 +1,14 @@
+//go:build !fakepayment
+
+package app
+
+import (
+	"ap2final_ticket_service/internal/config"
+	"ap2final_ticket_service/internal/payment"
+	"errors"
+	"log/slog"
+)
+
+func newFakePaymentService(config.PaymentHTTP, *slog.Logger) (payment.Service, func(), error) {
+	return nil, nil, errors.New(`payment provider "fake" is only available in builds with the fakepayment tag`)
+}
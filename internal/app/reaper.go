@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"github.com/sorawaslocked/ap2final_base/pkg/logger"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type sweeper interface {
+	ExpireReservations(ctx context.Context) (int, error)
+	RetryPendingRefunds(ctx context.Context) (int, error)
+}
+
+// reaper periodically moves unpaid reservations past their hold to EXPIRED
+// and retries refunds the payment provider failed to pay.
+type reaper struct {
+	uc       sweeper
+	interval time.Duration
+	log      *slog.Logger
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newReaper(uc sweeper, interval time.Duration, log *slog.Logger) *reaper {
+	return &reaper{
+		uc:       uc,
+		interval: interval,
+		log:      log,
+	}
+}
+
+func (r *reaper) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.log.Info("starting reservation reaper", slog.Duration("interval", r.interval))
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.sweep(ctx)
+			}
+		}
+	}()
+}
+
+func (r *reaper) sweep(ctx context.Context) {
+	n, err := r.uc.ExpireReservations(ctx)
+	if err != nil {
+		r.log.Error("error expiring reservations", logger.Err(err))
+	} else if n > 0 {
+		r.log.Info("expired stale reservations", slog.Int("count", n))
+	}
+
+	n, err = r.uc.RetryPendingRefunds(ctx)
+	if err != nil {
+		r.log.Error("error retrying pending refunds", logger.Err(err))
+	} else if n > 0 {
+		r.log.Info("settled pending refunds", slog.Int("count", n))
+	}
+}
+
+func (r *reaper) stop() {
+	r.log.Info("stopping reservation reaper")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
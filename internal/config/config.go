@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/sorawaslocked/ap2final_base/pkg/grpc"
 	"github.com/sorawaslocked/ap2final_base/pkg/mongo"
@@ -11,10 +12,23 @@ import (
 
 type (
 	Config struct {
-		Env    string       `yaml:"env" env-required:"true"`
-		Mongo  mongo.Config `yaml:"mongo" env-required:"true"`
-		Redis  Redis        `yaml:"redis" env-required:"true"`
-		Server Server       `yaml:"server" env-required:"true"`
+		Env         string       `yaml:"env" env-required:"true"`
+		Mongo       mongo.Config `yaml:"mongo" env-required:"true"`
+		Redis       Redis        `yaml:"redis" env-required:"true"`
+		Server      Server       `yaml:"server" env-required:"true"`
+		Reservation Reservation  `yaml:"reservation"`
+		Layout      Layout       `yaml:"layout"`
+		Payment     Payment      `yaml:"payment"`
+		Pricing     Pricing      `yaml:"pricing"`
+		Reports     Reports      `yaml:"reports"`
+		Auth        Auth         `yaml:"auth"`
+	}
+
+	// Auth.JWTSecret verifies the bearer tokens the auth service issues.
+	// Without it every request is anonymous and tokens are refused, so
+	// admin-only changes such as price corrections are unavailable.
+	Auth struct {
+		JWTSecret string `yaml:"jwtSecret" env:"JWT_SECRET"`
 	}
 
 	Server struct {
@@ -27,6 +41,77 @@ type (
 		Password string        `yaml:"password" env-default:""`
 		DB       int           `yaml:"db" env-default:"0"`
 		TTL      time.Duration `yaml:"ttl" env-default:"24h"`
+		LockTTL  time.Duration `yaml:"lockTtl" env-default:"10s"`
+		// ReportTTL is how long analytics reports are served from cache.
+		ReportTTL time.Duration `yaml:"reportTtl" env-default:"1m"`
+	}
+
+	Reservation struct {
+		HoldDuration time.Duration `yaml:"holdDuration" env-default:"15m"`
+		ReapInterval time.Duration `yaml:"reapInterval" env-default:"1m"`
+	}
+
+	// Layout selects where hall layouts come from: the "halls" mongo
+	// collection or a JSON/YAML file at Path. Sessions without a layout are
+	// rejected unless AllowUnmappedSessions is set, in which case any seat
+	// number is accepted for them.
+	Layout struct {
+		Source                string `yaml:"source" env-default:"mongo"`
+		Path                  string `yaml:"path"`
+		AllowUnmappedSessions bool   `yaml:"allowUnmappedSessions" env-default:"false"`
+	}
+
+	// Payment.Provider is "mock", "http" for a real gateway at HTTP.BaseURL,
+	// or "fake" for an in-process stand-in gateway that approves everything
+	// (only in builds with the fakepayment tag).
+	Payment struct {
+		Provider string      `yaml:"provider" env-default:"mock"`
+		Currency string      `yaml:"currency" env-default:"KZT"`
+		HTTP     PaymentHTTP `yaml:"http"`
+	}
+
+	// Pricing computes a seat's price from BasePrice, or the session's own
+	// base price, scaled by the multiplier of the first matching seat
+	// category and time-of-day rule, and by WeekendMultiplier on Saturdays
+	// and Sundays. Prices are in major units of Payment.Currency.
+	Pricing struct {
+		BasePrice         float64         `yaml:"basePrice" env-default:"2000"`
+		Timezone          string          `yaml:"timezone" env-default:"UTC"`
+		WeekendMultiplier float64         `yaml:"weekendMultiplier" env-default:"1"`
+		SeatCategories    []SeatCategory  `yaml:"seatCategories"`
+		TimeOfDay         []TimeOfDayRule `yaml:"timeOfDay"`
+	}
+
+	// SeatCategory matches seats listed in Seats or lying in one of Rows.
+	SeatCategory struct {
+		Name       string   `yaml:"name"`
+		Rows       []string `yaml:"rows"`
+		Seats      []string `yaml:"seats"`
+		Multiplier float64  `yaml:"multiplier"`
+	}
+
+	// TimeOfDayRule matches showtimes starting in [From, To), given as
+	// "15:04". A window may wrap past midnight.
+	TimeOfDayRule struct {
+		From       string  `yaml:"from"`
+		To         string  `yaml:"to"`
+		Multiplier float64 `yaml:"multiplier"`
+	}
+
+	// Reports.Timezone is the IANA zone daily report buckets are cut in.
+	// Reports.Capacity is the seat count assumed for occupancy of sessions
+	// without a hall layout; zero leaves such sessions unreported.
+	Reports struct {
+		Timezone string `yaml:"timezone" env-default:"UTC"`
+		Capacity int    `yaml:"capacity" env-default:"0"`
+	}
+
+	PaymentHTTP struct {
+		BaseURL      string        `yaml:"baseUrl" env:"PAYMENT_BASE_URL"`
+		APIKey       string        `yaml:"apiKey" env:"PAYMENT_API_KEY"`
+		Timeout      time.Duration `yaml:"timeout" env-default:"5s"`
+		MaxRetries   int           `yaml:"maxRetries" env-default:"2"`
+		RetryBackoff time.Duration `yaml:"retryBackoff" env-default:"200ms"`
 	}
 )
 
@@ -47,9 +132,52 @@ func MustLoad() *Config {
 		panic("failed to load config")
 	}
 
+	if err := cfg.Reservation.Validate(); err != nil {
+		panic("invalid config: " + err.Error())
+	}
+
+	if err := cfg.Redis.Validate(); err != nil {
+		panic("invalid config: " + err.Error())
+	}
+
 	return &cfg
 }
 
+// Validate rejects durations the reservation hold and the reaper's ticker
+// cannot work with.
+func (r Reservation) Validate() error {
+	if r.HoldDuration <= 0 {
+		return fmt.Errorf("reservation.holdDuration must be positive, got %s", r.HoldDuration)
+	}
+
+	if r.ReapInterval <= 0 {
+		return fmt.Errorf("reservation.reapInterval must be positive, got %s", r.ReapInterval)
+	}
+
+	return nil
+}
+
+// Validate rejects a seat lock TTL that would leave a lock without expiry,
+// so a holder that crashes cannot block its seat forever.
+func (r Redis) Validate() error {
+	if r.LockTTL <= 0 {
+		return fmt.Errorf("redis.lockTtl must be positive, got %s", r.LockTTL)
+	}
+
+	return nil
+}
+
+// MaxCallDuration is the longest one provider call can take, counting
+// every retry and the backoff between them.
+func (p PaymentHTTP) MaxCallDuration() time.Duration {
+	total := p.Timeout * time.Duration(p.MaxRetries+1)
+	for attempt := 1; attempt <= p.MaxRetries; attempt++ {
+		total += p.RetryBackoff * time.Duration(1<<(attempt-1))
+	}
+
+	return total
+}
+
 func fetchConfigPath() string {
 	var res string
 
@@ -1,35 +1,102 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
 
 var (
 	// Ticket related errors
-	ErrTicketNotFound      = errors.New("Ticket not found")
-	ErrTicketAlreadyPaid   = errors.New("Ticket already paid")
-	ErrTicketAlreadyExists = errors.New("Ticket already exists")
-	ErrTicketNotReserved   = errors.New("Ticket not reserved")
-	ErrTicketExpired       = errors.New("Ticket reservation has expired")
-	ErrTicketCancelled     = errors.New("Ticket has been cancelled")
+	ErrTicketNotFound        = errors.New("Ticket not found")
+	ErrTicketAlreadyPaid     = errors.New("Ticket already paid")
+	ErrTicketAlreadyExists   = errors.New("Ticket already exists")
+	ErrTicketNotReserved     = errors.New("Ticket not reserved")
+	ErrTicketExpired         = errors.New("Ticket reservation has expired")
+	ErrTicketCancelled       = errors.New("Ticket has been cancelled")
+	ErrTicketNotPaid         = errors.New("Ticket not paid")
+	ErrTicketRefunded        = errors.New("Ticket has been refunded")
+	ErrTicketUsed            = errors.New("Ticket has already been used")
+	ErrInvalidTicketStatus   = errors.New("Invalid ticket status")
+	ErrTicketVersionConflict = errors.New("Ticket was modified concurrently")
+
+	// Transfer related errors
+	ErrTransferNotFound       = errors.New("Ticket transfer not found")
+	ErrTransferNotPending     = errors.New("Ticket transfer is no longer pending")
+	ErrTransferAlreadyPending = errors.New("Ticket already has a pending transfer")
 
 	// Seat/session related errors
 	ErrSeatAlreadyTaken  = errors.New("Seat already taken")
+	ErrSeatLocked        = errors.New("Seat is being reserved by another request")
 	ErrInvalidSeatNumber = errors.New("Invalid seat number")
 	ErrSessionNotFound   = errors.New("Movie session not found")
 	ErrSessionNotActive  = errors.New("Movie session not active")
 	ErrSessionFull       = errors.New("Movie session is full")
+	ErrHallNotFound      = errors.New("Hall layout not found")
+	ErrInvalidHallLayout = errors.New("Invalid hall layout")
+	ErrShowtimeStarted   = errors.New("Showtime has already started")
 
 	// Payment related errors
 	ErrPaymentFailed        = errors.New("Payment processing failed")
 	ErrInsufficientFunds    = errors.New("Insufficient funds for payment")
 	ErrInvalidPaymentMethod = errors.New("Invalid payment method")
+	ErrRefundFailed         = errors.New("Refund processing failed")
+	ErrPaymentNotFound      = errors.New("Payment not found")
+	ErrPaymentInProgress    = errors.New("Payment for this ticket is already in progress")
+	ErrCurrencyMismatch     = errors.New("Currency mismatch")
+	ErrPriceMismatch        = errors.New("Price does not match the current price")
+
+	// Promo code related errors
+	ErrPromoCodeNotFound      = errors.New("Promo code not found")
+	ErrPromoCodeAlreadyExists = errors.New("Promo code already exists")
+	ErrInvalidPromoCode       = errors.New("Invalid promo code")
+	ErrPromoCodeNotActive     = errors.New("Promo code is not active")
+	ErrPromoCodeNotApplicable = errors.New("Promo code does not apply to this session")
+	ErrPromoCodeExhausted     = errors.New("Promo code has been fully redeemed")
+	ErrPromoCodeUserLimit     = errors.New("Promo code usage limit reached for this user")
 
 	// User related errors
-	ErrUserNotFound   = errors.New("User not found")
-	ErrUserNotAllowed = errors.New("User is not allowed to perform this action")
+	ErrUserNotFound    = errors.New("User not found")
+	ErrUserNotAllowed  = errors.New("User is not allowed to perform this action")
+	ErrUnauthenticated = errors.New("User is not authenticated")
 
 	// Validation errors
-	ErrInvalidTicketData = errors.New("Invalid ticket data")
+	ErrInvalidTicketData    = errors.New("Invalid ticket data")
+	ErrInvalidTicketFilter  = errors.New("Invalid ticket filter")
+	ErrInvalidPageRequest   = errors.New("Invalid page request")
+	ErrInvalidCursor        = errors.New("Invalid page cursor")
+	ErrInvalidReportRequest = errors.New("Invalid report request")
 
 	// User related errors
 	ErrInvalidUserID = errors.New("Invalid user ID")
 )
+
+// SeatReservationError reports, per seat, why a group reservation was rejected.
+type SeatReservationError struct {
+	Seats map[string]error
+}
+
+func (e *SeatReservationError) Error() string {
+	seats := make([]string, 0, len(e.Seats))
+	for seat := range e.Seats {
+		seats = append(seats, seat)
+	}
+	sort.Strings(seats)
+
+	parts := make([]string, len(seats))
+	for i, seat := range seats {
+		parts[i] = fmt.Sprintf("%s: %v", seat, e.Seats[seat])
+	}
+
+	return "Seats could not be reserved: " + strings.Join(parts, "; ")
+}
+
+func (e *SeatReservationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Seats))
+	for _, err := range e.Seats {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// ActorSystem is recorded for changes made by the service itself, such as expiry.
+const ActorSystem = "system"
+
+// RoleAdmin marks an operator allowed to make administrative changes,
+// such as correcting a ticket's price.
+const RoleAdmin = "admin"
+
+// TicketEvent records one status change of a ticket.
+type TicketEvent struct {
+	ID        string
+	TicketID  string
+	Actor     string
+	Reason    string
+	OldStatus TicketStatus
+	NewStatus TicketStatus
+	CreatedAt time.Time
+}
+
+type (
+	actorKey     struct{}
+	actorRoleKey struct{}
+)
+
+// WithActor attaches the ID of whoever is acting on tickets to ctx.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor attached to ctx, or ActorSystem.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+
+	return ActorSystem
+}
+
+// UserFromContext returns the user whose verified credential is attached
+// to ctx. It reports false for anonymous requests and the service itself.
+func UserFromContext(ctx context.Context) (string, bool) {
+	actor := ActorFromContext(ctx)
+
+	return actor, actor != ActorSystem
+}
+
+// WithActorRole attaches the role of whoever is acting on tickets to ctx.
+// Only roles proven by a verified credential may be attached.
+func WithActorRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, actorRoleKey{}, role)
+}
+
+// IsAdmin reports whether the actor attached to ctx has RoleAdmin. The
+// service itself, acting without a request, is not an admin.
+func IsAdmin(ctx context.Context) bool {
+	role, _ := ctx.Value(actorRoleKey{}).(string)
+	return role == RoleAdmin
+}
@@ -0,0 +1,110 @@
+package models
+
+import "fmt"
+
+type Hall struct {
+	ID            string
+	Name          string
+	Rows          []HallRow
+	DisabledSeats []string
+	SessionIDs    []string
+}
+
+type HallRow struct {
+	Label       string
+	SeatNumbers []string
+}
+
+// NewHallRow builds a row of count seats numbered <label>1 … <label><count>.
+func NewHallRow(label string, count int) HallRow {
+	seats := make([]string, count)
+	for i := range seats {
+		seats[i] = fmt.Sprintf("%s%d", label, i+1)
+	}
+
+	return HallRow{Label: label, SeatNumbers: seats}
+}
+
+// SeatNumbers returns every bookable seat in the hall, in layout order.
+func (h Hall) SeatNumbers() []string {
+	disabled := make(map[string]struct{}, len(h.DisabledSeats))
+	for _, seat := range h.DisabledSeats {
+		disabled[seat] = struct{}{}
+	}
+
+	var seats []string
+	for _, row := range h.Rows {
+		for _, seat := range row.SeatNumbers {
+			if _, ok := disabled[seat]; !ok {
+				seats = append(seats, seat)
+			}
+		}
+	}
+
+	return seats
+}
+
+// SeatOrder maps every seat in the layout, disabled ones included, to its
+// position in layout order.
+func (h Hall) SeatOrder() map[string]int {
+	order := make(map[string]int)
+	for _, row := range h.Rows {
+		for _, seat := range row.SeatNumbers {
+			order[seat] = len(order)
+		}
+	}
+
+	return order
+}
+
+// HasSeat reports whether seatNumber exists in the layout and is not disabled.
+func (h Hall) HasSeat(seatNumber string) bool {
+	for _, seat := range h.DisabledSeats {
+		if seat == seatNumber {
+			return false
+		}
+	}
+
+	for _, row := range h.Rows {
+		for _, seat := range row.SeatNumbers {
+			if seat == seatNumber {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Validate rejects layouts whose seats cannot be told apart: empty rows,
+// seat numbers used twice and disabled seats the layout does not have.
+func (h Hall) Validate() error {
+	seats := make(map[string]struct{})
+	for _, row := range h.Rows {
+		if len(row.SeatNumbers) == 0 {
+			return fmt.Errorf("%w: row %q has no seats", ErrInvalidHallLayout, row.Label)
+		}
+
+		for _, seat := range row.SeatNumbers {
+			if seat == "" {
+				return fmt.Errorf("%w: row %q has an empty seat number", ErrInvalidHallLayout, row.Label)
+			}
+			if _, ok := seats[seat]; ok {
+				return fmt.Errorf("%w: seat %s appears more than once", ErrInvalidHallLayout, seat)
+			}
+			seats[seat] = struct{}{}
+		}
+	}
+
+	for _, seat := range h.DisabledSeats {
+		if _, ok := seats[seat]; !ok {
+			return fmt.Errorf("%w: disabled seat %s is not in the layout", ErrInvalidHallLayout, seat)
+		}
+	}
+
+	return nil
+}
+
+func (h Hall) Capacity() int {
+	return len(h.SeatNumbers())
+}
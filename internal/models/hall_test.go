@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHallValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hall    Hall
+		wantErr error
+	}{
+		{
+			name: "valid",
+			hall: Hall{
+				Rows:          []HallRow{NewHallRow("A", 3), {Label: "B", SeatNumbers: []string{"B1", "B2"}}},
+				DisabledSeats: []string{"A2"},
+			},
+		},
+		{
+			name:    "empty row",
+			hall:    Hall{Rows: []HallRow{NewHallRow("A", 3), {Label: "B"}}},
+			wantErr: ErrInvalidHallLayout,
+		},
+		{
+			name:    "seat repeated within a row",
+			hall:    Hall{Rows: []HallRow{{Label: "A", SeatNumbers: []string{"A1", "A1"}}}},
+			wantErr: ErrInvalidHallLayout,
+		},
+		{
+			name:    "seat repeated across rows",
+			hall:    Hall{Rows: []HallRow{NewHallRow("A", 3), {Label: "A'", SeatNumbers: []string{"A3"}}}},
+			wantErr: ErrInvalidHallLayout,
+		},
+		{
+			name:    "empty seat number",
+			hall:    Hall{Rows: []HallRow{{Label: "A", SeatNumbers: []string{"A1", ""}}}},
+			wantErr: ErrInvalidHallLayout,
+		},
+		{
+			name:    "disabled seat outside the layout",
+			hall:    Hall{Rows: []HallRow{NewHallRow("A", 3)}, DisabledSeats: []string{"A4"}},
+			wantErr: ErrInvalidHallLayout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hall.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
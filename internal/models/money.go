@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Money is an exact amount in the minor units of an ISO 4217 currency,
+// e.g. {Amount: 150050, Currency: "KZT"} is 1500.50 KZT.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// minorUnitDigits lists currencies whose minor unit is not 1/100.
+var minorUnitDigits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// MinorUnitFactor returns how many minor units make one major unit of currency.
+func MinorUnitFactor(currency string) int64 {
+	digits, ok := minorUnitDigits[currency]
+	if !ok {
+		digits = 2
+	}
+
+	factor := int64(1)
+	for i := 0; i < digits; i++ {
+		factor *= 10
+	}
+
+	return factor
+}
+
+// MoneyFromFloat converts a major-unit amount, rounding half away from zero
+// to the nearest minor unit. Use it only at boundaries that still speak floats.
+func MoneyFromFloat(value float64, currency string) Money {
+	return Money{
+		Amount:   int64(math.Round(value * float64(MinorUnitFactor(currency)))),
+		Currency: currency,
+	}
+}
+
+// Float returns the amount in major units for boundaries that still speak floats.
+func (m Money) Float() float64 {
+	return float64(m.Amount) / float64(MinorUnitFactor(m.Currency))
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add sums two amounts of the same currency. A zero value without a
+// currency is treated as zero of the other operand's currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency == "" && m.Amount == 0 {
+		return other, nil
+	}
+
+	if other.Currency == "" && other.Amount == 0 {
+		return m, nil
+	}
+
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+func (m Money) String() string {
+	factor := MinorUnitFactor(m.Currency)
+	if factor == 1 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := len(fmt.Sprint(factor)) - 1
+
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/factor, digits, amount%factor, m.Currency)
+}
+
+func (m Money) Ptr() *Money { return &m }
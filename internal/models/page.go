@@ -0,0 +1,61 @@
+package models
+
+import "fmt"
+
+type TicketSortField string
+
+const (
+	TicketSortCreatedAt    TicketSortField = "created_at"
+	TicketSortPurchaseTime TicketSortField = "purchase_time"
+	TicketSortPrice        TicketSortField = "price"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// PageRequest selects one page of a ticket listing. Cursor is the opaque
+// NextCursor of the previous page and must be used with the same sort.
+// A zero Size means no limit.
+type PageRequest struct {
+	Size       int
+	Cursor     string
+	SortBy     TicketSortField
+	Descending bool
+}
+
+// TicketPage is one page of a ticket listing. NextCursor is empty on the
+// last page.
+type TicketPage struct {
+	Tickets    []*Ticket
+	NextCursor string
+}
+
+// Normalize applies the default page size and sort, and rejects sizes and
+// sort fields the listing endpoints do not support.
+func (p PageRequest) Normalize() (PageRequest, error) {
+	switch {
+	case p.Size < 0:
+		return PageRequest{}, fmt.Errorf("%w: page size cannot be negative", ErrInvalidPageRequest)
+	case p.Size == 0:
+		p.Size = DefaultPageSize
+	case p.Size > MaxPageSize:
+		p.Size = MaxPageSize
+	}
+
+	switch p.SortBy {
+	case "":
+		p.SortBy = TicketSortCreatedAt
+	case TicketSortCreatedAt, TicketSortPurchaseTime, TicketSortPrice:
+	default:
+		return PageRequest{}, fmt.Errorf("%w: cannot sort by %q", ErrInvalidPageRequest, p.SortBy)
+	}
+
+	return p, nil
+}
+
+// IsFirstDefault reports whether p asks for the first page in default order.
+func (p PageRequest) IsFirstDefault() bool {
+	return p.Cursor == "" && p.Size == DefaultPageSize && p.SortBy == TicketSortCreatedAt && !p.Descending
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "PENDING"
+	PaymentStatusSucceeded         PaymentStatus = "SUCCEEDED"
+	PaymentStatusFailed            PaymentStatus = "FAILED"
+	PaymentStatusPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+	PaymentStatusRefunded          PaymentStatus = "REFUNDED"
+)
+
+// Payment is one charge attempt with the provider, covering one or more tickets.
+type Payment struct {
+	ID             string
+	TicketIDs      []string
+	Amount         Money
+	RefundedAmount Money
+	Method         string
+	ProviderRef    string
+	Status         PaymentStatus
+	FailureReason  string
+	ClaimKey       string
+	ClaimedAt      time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type PaymentFilter struct {
+	ID          *string
+	TicketID    *string
+	ProviderRef *string
+	Status      *PaymentStatus
+	ClaimKey    *string
+	// ClaimedAt matches the claim time exactly, so a takeover only applies
+	// while nobody else has taken the claim over. A zero time matches
+	// claims recorded without one.
+	ClaimedAt *time.Time
+}
+
+type PaymentUpdateData struct {
+	Status         *PaymentStatus
+	ProviderRef    *string
+	RefundedAmount *Money
+	FailureReason  *string
+	ClaimedAt      *time.Time
+}
+
+func (ps PaymentStatus) Ptr() *PaymentStatus { return &ps }
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercent DiscountType = "PERCENT"
+	DiscountTypeFixed   DiscountType = "FIXED"
+)
+
+// PromoCode is a discount campaign. Zero ValidFrom/ValidUntil leave the
+// window open on that side, zero limits mean unlimited, and empty
+// MovieIDs/SessionIDs mean the code applies everywhere.
+type PromoCode struct {
+	ID             string
+	Code           string
+	DiscountType   DiscountType
+	PercentOff     int64
+	AmountOff      Money
+	ValidFrom      time.Time
+	ValidUntil     time.Time
+	MaxRedemptions int64
+	MaxPerUser     int64
+	Redemptions    int64
+	MovieIDs       []string
+	SessionIDs     []string
+	CreatedAt      time.Time
+}
+
+// NormalizePromoCode makes code lookups case- and whitespace-insensitive.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks a new code's definition.
+func (p PromoCode) Validate() error {
+	if p.Code == "" {
+		return fmt.Errorf("%w: code is empty", ErrInvalidPromoCode)
+	}
+
+	switch p.DiscountType {
+	case DiscountTypePercent:
+		if p.PercentOff <= 0 || p.PercentOff > 100 {
+			return fmt.Errorf("%w: percent off must be between 1 and 100", ErrInvalidPromoCode)
+		}
+	case DiscountTypeFixed:
+		if p.AmountOff.Amount <= 0 || p.AmountOff.Currency == "" {
+			return fmt.Errorf("%w: amount off must be positive", ErrInvalidPromoCode)
+		}
+	default:
+		return fmt.Errorf("%w: unknown discount type %q", ErrInvalidPromoCode, p.DiscountType)
+	}
+
+	if !p.ValidFrom.IsZero() && !p.ValidUntil.IsZero() && !p.ValidUntil.After(p.ValidFrom) {
+		return fmt.Errorf("%w: validity window ends before it starts", ErrInvalidPromoCode)
+	}
+
+	if p.MaxRedemptions < 0 || p.MaxPerUser < 0 {
+		return fmt.Errorf("%w: usage limits cannot be negative", ErrInvalidPromoCode)
+	}
+
+	return nil
+}
+
+// CheckApplicable reports whether the code can be used at now for a
+// ticket to movieID's session sessionID. Usage limits are enforced when
+// the code is redeemed.
+func (p PromoCode) CheckApplicable(now time.Time, movieID, sessionID string) error {
+	if !p.ValidFrom.IsZero() && now.Before(p.ValidFrom) {
+		return ErrPromoCodeNotActive
+	}
+
+	if !p.ValidUntil.IsZero() && !now.Before(p.ValidUntil) {
+		return ErrPromoCodeNotActive
+	}
+
+	if p.MaxRedemptions > 0 && p.Redemptions >= p.MaxRedemptions {
+		return ErrPromoCodeExhausted
+	}
+
+	if !p.AppliesTo(movieID, sessionID) {
+		return ErrPromoCodeNotApplicable
+	}
+
+	return nil
+}
+
+// AppliesTo reports whether the code covers movieID's session sessionID,
+// regardless of its validity window and usage limits.
+func (p PromoCode) AppliesTo(movieID, sessionID string) bool {
+	if len(p.MovieIDs) > 0 && !contains(p.MovieIDs, movieID) {
+		return false
+	}
+
+	return len(p.SessionIDs) == 0 || contains(p.SessionIDs, sessionID)
+}
+
+// Apply returns the discounted price and the discount taken off it.
+// Prices never go below zero.
+func (p PromoCode) Apply(price Money) (Money, Money, error) {
+	var discount Money
+
+	switch p.DiscountType {
+	case DiscountTypePercent:
+		discount = Money{Amount: (price.Amount*p.PercentOff + 50) / 100, Currency: price.Currency}
+	case DiscountTypeFixed:
+		if p.AmountOff.Currency != price.Currency {
+			return Money{}, Money{}, fmt.Errorf("%w: promo code %s is in %s", ErrCurrencyMismatch, p.Code, p.AmountOff.Currency)
+		}
+		discount = p.AmountOff
+	default:
+		return Money{}, Money{}, fmt.Errorf("%w: unknown discount type %q", ErrInvalidPromoCode, p.DiscountType)
+	}
+
+	if discount.Amount > price.Amount {
+		discount.Amount = price.Amount
+	}
+
+	return Money{Amount: price.Amount - discount.Amount, Currency: price.Currency}, discount, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
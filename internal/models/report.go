@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type ReportGroupBy string
+
+const (
+	ReportGroupByMovie   ReportGroupBy = "movie"
+	ReportGroupBySession ReportGroupBy = "session"
+	ReportGroupByDay     ReportGroupBy = "day"
+)
+
+// MaxReportRange bounds the period a single report may cover.
+const MaxReportRange = 366 * 24 * time.Hour
+
+func (g ReportGroupBy) IsValid() bool {
+	switch g {
+	case ReportGroupByMovie, ReportGroupBySession, ReportGroupByDay:
+		return true
+	default:
+		return false
+	}
+}
+
+// SalesReportQuery covers ticket activity in [From, To): reservations made,
+// tickets sold and refunds issued in that period.
+type SalesReportQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy ReportGroupBy
+}
+
+func (q SalesReportQuery) Validate() error {
+	if !q.GroupBy.IsValid() {
+		return fmt.Errorf("%w: cannot group by %q", ErrInvalidReportRequest, q.GroupBy)
+	}
+
+	if q.From.IsZero() || q.To.IsZero() {
+		return fmt.Errorf("%w: period must have both ends", ErrInvalidReportRequest)
+	}
+
+	if !q.To.After(q.From) {
+		return fmt.Errorf("%w: period ends before it starts", ErrInvalidReportRequest)
+	}
+
+	if q.To.Sub(q.From) > MaxReportRange {
+		return fmt.Errorf("%w: period is longer than %s", ErrInvalidReportRequest, MaxReportRange)
+	}
+
+	return nil
+}
+
+// SalesReportRow aggregates the activity of one group. Key is a movie ID,
+// a session ID or a day formatted as 2006-01-02 in the report timezone.
+// Reserved counts tickets reserved in the period. Paid counts tickets
+// purchased in it, including those used or refunded since, and Revenue is
+// the gross price they were bought at, plus what paid exchanges made in the
+// period charged or owe back. Refunded is what was paid back in the period,
+// for whenever the ticket was bought. ConversionRate is Paid over Reserved.
+type SalesReportRow struct {
+	Key            string
+	Reserved       int64
+	Paid           int64
+	Revenue        Money
+	Refunded       Money
+	NetRevenue     Money
+	ConversionRate float64
+}
+
+type SalesReport struct {
+	SalesReportQuery
+	Rows        []SalesReportRow
+	GeneratedAt time.Time
+}
+
+// PaidTicketStatuses are the statuses a ticket can only reach by being paid.
+func PaidTicketStatuses() []TicketStatus {
+	return []TicketStatus{TicketStatusPaid, TicketStatusUsed, TicketStatusRefunded}
+}
+
+type OccupancyInterval string
+
+const (
+	OccupancyIntervalHour OccupancyInterval = "hour"
+	OccupancyIntervalDay  OccupancyInterval = "day"
+)
+
+func (i OccupancyInterval) IsValid() bool {
+	return i == OccupancyIntervalHour || i == OccupancyIntervalDay
+}
+
+// OccupancyDelta counts what happened to a session's seats within one
+// interval starting at At: seats taken by reservation or exchange, seats
+// sold, unpaid holds given back by cancellation, expiry or exchange, and
+// sold seats given back by refund or exchange. A ticket exchanged in
+// already paid counts as both reserved and paid.
+type OccupancyDelta struct {
+	At           time.Time
+	Reserved     int
+	Paid         int
+	ReleasedHeld int
+	ReleasedSold int
+}
+
+// OccupancyPoint is the state of a session's seats at the end of the
+// interval starting at At. FillPercent is the share of capacity sold.
+type OccupancyPoint struct {
+	At          time.Time
+	Sold        int
+	Held        int
+	Free        int
+	FillPercent float64
+}
+
+// OccupancyReport gives the latest state of a session's seats and how it
+// got there, one point per interval that saw any activity.
+type OccupancyReport struct {
+	SessionID   string
+	Capacity    int
+	Interval    OccupancyInterval
+	Current     OccupancyPoint
+	Series      []OccupancyPoint
+	GeneratedAt time.Time
+}
+
+// NewOccupancyReport replays deltas, which must be in time order, against
+// capacity.
+func NewOccupancyReport(sessionID string, capacity int, interval OccupancyInterval, deltas []OccupancyDelta) OccupancyReport {
+	report := OccupancyReport{
+		SessionID: sessionID,
+		Capacity:  capacity,
+		Interval:  interval,
+		Current:   newOccupancyPoint(time.Time{}, capacity, 0, 0),
+		Series:    make([]OccupancyPoint, 0, len(deltas)),
+	}
+
+	sold, held := 0, 0
+	for _, delta := range deltas {
+		sold += delta.Paid - delta.ReleasedSold
+		held += delta.Reserved - delta.Paid - delta.ReleasedHeld
+
+		report.Current = newOccupancyPoint(delta.At, capacity, sold, held)
+		report.Series = append(report.Series, report.Current)
+	}
+
+	return report
+}
+
+func newOccupancyPoint(at time.Time, capacity, sold, held int) OccupancyPoint {
+	point := OccupancyPoint{
+		At:   at,
+		Sold: sold,
+		Held: held,
+		Free: max(capacity-sold-held, 0),
+	}
+
+	if capacity > 0 {
+		point.FillPercent = float64(sold) / float64(capacity) * 100
+	}
+
+	return point
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewOccupancyReport(t *testing.T) {
+	hour := func(h int) time.Time { return time.Date(2026, 3, 1, h, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		capacity int
+		deltas   []OccupancyDelta
+		want     []OccupancyPoint
+	}{
+		{name: "no activity", capacity: 10, want: []OccupancyPoint{}},
+		{
+			name:     "reserved and paid in different buckets",
+			capacity: 10,
+			deltas: []OccupancyDelta{
+				{At: hour(9), Reserved: 3},
+				{At: hour(10), Paid: 2},
+				{At: hour(11), Reserved: 1, Paid: 1},
+			},
+			want: []OccupancyPoint{
+				{At: hour(9), Held: 3, Free: 7},
+				{At: hour(10), Sold: 2, Held: 1, Free: 7, FillPercent: 20},
+				{At: hour(11), Sold: 3, Held: 1, Free: 6, FillPercent: 30},
+			},
+		},
+		{
+			name:     "held seats released in a later bucket",
+			capacity: 4,
+			deltas: []OccupancyDelta{
+				{At: hour(9), Reserved: 4},
+				{At: hour(10), Paid: 1, ReleasedHeld: 2},
+			},
+			want: []OccupancyPoint{
+				{At: hour(9), Held: 4},
+				{At: hour(10), Sold: 1, Held: 1, Free: 2, FillPercent: 25},
+			},
+		},
+		{
+			name:     "sold seat refunded",
+			capacity: 4,
+			deltas: []OccupancyDelta{
+				{At: hour(9), Reserved: 2, Paid: 2},
+				{At: hour(12), ReleasedSold: 1},
+			},
+			want: []OccupancyPoint{
+				{At: hour(9), Sold: 2, Free: 2, FillPercent: 50},
+				{At: hour(12), Sold: 1, Free: 3, FillPercent: 25},
+			},
+		},
+		{
+			name:     "free seats never go negative",
+			capacity: 1,
+			deltas:   []OccupancyDelta{{At: hour(9), Reserved: 2}},
+			want:     []OccupancyPoint{{At: hour(9), Held: 2}},
+		},
+		{
+			name:     "unknown capacity has no fill percentage",
+			capacity: 0,
+			deltas:   []OccupancyDelta{{At: hour(9), Reserved: 1, Paid: 1}},
+			want:     []OccupancyPoint{{At: hour(9), Sold: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewOccupancyReport("s1", tt.capacity, OccupancyIntervalHour, tt.deltas)
+
+			if !reflect.DeepEqual(report.Series, tt.want) {
+				t.Errorf("Series =\n%+v\nwant\n%+v", report.Series, tt.want)
+			}
+
+			wantCurrent := OccupancyPoint{Free: tt.capacity}
+			if n := len(tt.want); n > 0 {
+				wantCurrent = tt.want[n-1]
+			}
+			if report.Current != wantCurrent {
+				t.Errorf("Current = %+v, want %+v", report.Current, wantCurrent)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+type SeatState string
+
+const (
+	SeatStateFree     SeatState = "FREE"
+	SeatStateReserved SeatState = "RESERVED"
+	SeatStatePaid     SeatState = "PAID"
+)
+
+// SeatMap is the state of every known seat in one session. Seats missing
+// from the map are free.
+type SeatMap struct {
+	SessionID string
+	Seats     map[string]SeatState
+}
+
+// State returns the state of a seat, treating unknown seats as free.
+func (m SeatMap) State(seatNumber string) SeatState {
+	if state, ok := m.Seats[seatNumber]; ok {
+		return state
+	}
+
+	return SeatStateFree
+}
+
+// SeatStateFromTicketStatus maps a ticket status onto the state of the seat it holds.
+func SeatStateFromTicketStatus(status TicketStatus) SeatState {
+	switch status {
+	case TicketStatusReserved:
+		return SeatStateReserved
+	case TicketStatusPaid:
+		return SeatStatePaid
+	default:
+		return SeatStateFree
+	}
+}
+
+// CompareSeatNumbers orders seat numbers the way they read in a hall: by
+// row label, shorter labels first so that "Z" precedes "AA", then by seat
+// number, so that "A2" precedes "A10". It returns -1, 0 or +1 like
+// strings.Compare.
+func CompareSeatNumbers(a, b string) int {
+	rowA, numA := splitSeatNumber(a)
+	rowB, numB := splitSeatNumber(b)
+
+	if c := cmp.Compare(len(rowA), len(rowB)); c != 0 {
+		return c
+	}
+
+	if c := strings.Compare(rowA, rowB); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(numA, numB); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
+
+// splitSeatNumber splits a seat number such as "B12" into its row label and
+// trailing number. Seats without a trailing number get -1.
+func splitSeatNumber(seatNumber string) (string, int) {
+	i := len(seatNumber)
+	for i > 0 && seatNumber[i-1] >= '0' && seatNumber[i-1] <= '9' {
+		i--
+	}
+
+	num, err := strconv.Atoi(seatNumber[i:])
+	if err != nil {
+		return seatNumber[:i], -1
+	}
+
+	return seatNumber[:i], num
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Session is a scheduled showtime as far as pricing and transfers need it.
+type Session struct {
+	ID       string
+	MovieID  string
+	HallID   string
+	StartsAt time.Time
+	// BasePrice overrides the configured base price when set.
+	BasePrice *Money
+}
+
+// HasStarted reports whether the showtime has begun at now. Sessions
+// without a start time never do.
+func (s Session) HasStarted(now time.Time) bool {
+	return !s.StartsAt.IsZero() && !now.Before(s.StartsAt)
+}
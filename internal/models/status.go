@@ -0,0 +1,82 @@
+package models
+
+// ticketTransitions lists, for every status, the statuses a ticket may move to.
+var ticketTransitions = map[TicketStatus][]TicketStatus{
+	TicketStatusReserved:  {TicketStatusPaid, TicketStatusCancelled, TicketStatusExpired},
+	TicketStatusPaid:      {TicketStatusRefunded, TicketStatusUsed},
+	TicketStatusCancelled: {},
+	TicketStatusExpired:   {},
+	TicketStatusRefunded:  {},
+	TicketStatusUsed:      {},
+}
+
+// illegalTransitionErrs is the error returned when a ticket in the given
+// status is asked to move somewhere the table does not allow.
+var illegalTransitionErrs = map[TicketStatus]error{
+	TicketStatusReserved:  ErrTicketNotPaid,
+	TicketStatusPaid:      ErrTicketAlreadyPaid,
+	TicketStatusCancelled: ErrTicketCancelled,
+	TicketStatusExpired:   ErrTicketExpired,
+	TicketStatusRefunded:  ErrTicketRefunded,
+	TicketStatusUsed:      ErrTicketUsed,
+}
+
+// TicketStatuses returns every known ticket status.
+func TicketStatuses() []TicketStatus {
+	return []TicketStatus{
+		TicketStatusReserved,
+		TicketStatusPaid,
+		TicketStatusCancelled,
+		TicketStatusExpired,
+		TicketStatusRefunded,
+		TicketStatusUsed,
+	}
+}
+
+func (ts TicketStatus) IsValid() bool {
+	_, ok := ticketTransitions[ts]
+	return ok
+}
+
+// IsTerminal reports whether no further transitions are possible.
+func (ts TicketStatus) IsTerminal() bool {
+	return len(ticketTransitions[ts]) == 0
+}
+
+// EnsureActive returns nil while a ticket in ts can still change, and for
+// a terminal status the error describing it, such as ErrTicketUsed.
+func (ts TicketStatus) EnsureActive() error {
+	if !ts.IsValid() {
+		return ErrInvalidTicketStatus
+	}
+
+	if ts.IsTerminal() {
+		return illegalTransitionErrs[ts]
+	}
+
+	return nil
+}
+
+func (ts TicketStatus) CanTransitionTo(next TicketStatus) bool {
+	for _, allowed := range ticketTransitions[ts] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransitionTo validates moving a ticket from ts to next and returns the
+// error describing why the move is illegal, or nil if it is allowed.
+func (ts TicketStatus) TransitionTo(next TicketStatus) error {
+	if !ts.IsValid() || !next.IsValid() {
+		return ErrInvalidTicketStatus
+	}
+
+	if ts.CanTransitionTo(next) {
+		return nil
+	}
+
+	return illegalTransitionErrs[ts]
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTicketStatusTransitionTo(t *testing.T) {
+	allowed := map[TicketStatus][]TicketStatus{
+		TicketStatusReserved: {TicketStatusPaid, TicketStatusCancelled, TicketStatusExpired},
+		TicketStatusPaid:     {TicketStatusRefunded, TicketStatusUsed},
+	}
+
+	// The error a ticket in each status reports for any move it cannot make.
+	rejected := map[TicketStatus]error{
+		TicketStatusReserved:  ErrTicketNotPaid,
+		TicketStatusPaid:      ErrTicketAlreadyPaid,
+		TicketStatusCancelled: ErrTicketCancelled,
+		TicketStatusExpired:   ErrTicketExpired,
+		TicketStatusRefunded:  ErrTicketRefunded,
+		TicketStatusUsed:      ErrTicketUsed,
+	}
+
+	for _, from := range TicketStatuses() {
+		for _, to := range TicketStatuses() {
+			want := rejected[from]
+			for _, next := range allowed[from] {
+				if next == to {
+					want = nil
+				}
+			}
+
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				if err := from.TransitionTo(to); !errors.Is(err, want) {
+					t.Errorf("TransitionTo() = %v, want %v", err, want)
+				}
+
+				if got := from.CanTransitionTo(to); got != (want == nil) {
+					t.Errorf("CanTransitionTo() = %v, want %v", got, want == nil)
+				}
+			})
+		}
+	}
+}
+
+func TestTicketStatusTransitionToUnknown(t *testing.T) {
+	unknown := TicketStatus("LOST")
+
+	tests := []struct {
+		name     string
+		from, to TicketStatus
+	}{
+		{name: "from unknown", from: unknown, to: TicketStatusPaid},
+		{name: "to unknown", from: TicketStatusReserved, to: unknown},
+		{name: "empty", from: "", to: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.from.TransitionTo(tt.to); !errors.Is(err, ErrInvalidTicketStatus) {
+				t.Errorf("TransitionTo() = %v, want %v", err, ErrInvalidTicketStatus)
+			}
+		})
+	}
+}
+
+func TestTicketStatusIsTerminal(t *testing.T) {
+	terminal := map[TicketStatus]bool{
+		TicketStatusReserved:  false,
+		TicketStatusPaid:      false,
+		TicketStatusCancelled: true,
+		TicketStatusExpired:   true,
+		TicketStatusRefunded:  true,
+		TicketStatusUsed:      true,
+	}
+
+	if len(terminal) != len(TicketStatuses()) {
+		t.Fatalf("test covers %d statuses, TicketStatuses() has %d", len(terminal), len(TicketStatuses()))
+	}
+
+	for status, want := range terminal {
+		t.Run(string(status), func(t *testing.T) {
+			if !status.IsValid() {
+				t.Fatalf("IsValid() = false")
+			}
+
+			if got := status.IsTerminal(); got != want {
+				t.Errorf("IsTerminal() = %v, want %v", got, want)
+			}
+
+			err := status.EnsureActive()
+			if want && err == nil {
+				t.Errorf("EnsureActive() = nil, want an error")
+			}
+			if !want && err != nil {
+				t.Errorf("EnsureActive() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestTicketStatusEnsureActiveUnknown(t *testing.T) {
+	if err := TicketStatus("LOST").EnsureActive(); !errors.Is(err, ErrInvalidTicketStatus) {
+		t.Errorf("EnsureActive() = %v, want %v", err, ErrInvalidTicketStatus)
+	}
+}
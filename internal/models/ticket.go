@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type TicketStatus string
 
@@ -8,21 +11,57 @@ const (
 	TicketStatusReserved  TicketStatus = "RESERVED"
 	TicketStatusPaid      TicketStatus = "PAID"
 	TicketStatusCancelled TicketStatus = "CANCELLED"
+	TicketStatusExpired   TicketStatus = "EXPIRED"
+	TicketStatusRefunded  TicketStatus = "REFUNDED"
+	TicketStatusUsed      TicketStatus = "USED"
 )
 
 type Ticket struct {
-	ID            string       `bson:"-"`
-	SessionID     string       `bson:"-"`
-	MovieID       string       `bson:"-"`
-	SeatNumber    string       `bson:"-"`
-	Price         float64      `bson:"-"`
-	Status        TicketStatus `bson:"-"`
-	UserID        string       `bson:"-"`
-	PurchaseTime  time.Time    `bson:"-"`
-	PaymentMethod string       `bson:"-"`
-	PaymentID     *string      `bson:"-"`
-	CreatedAt     time.Time    `bson:"-"`
-	UpdatedAt     time.Time    `bson:"-"`
+	ID            string         `bson:"-"`
+	SessionID     string         `bson:"-"`
+	MovieID       string         `bson:"-"`
+	SeatNumber    string         `bson:"-"`
+	Price         Money          `bson:"-"`
+	Status        TicketStatus   `bson:"-"`
+	UserID        string         `bson:"-"`
+	PurchaseTime  time.Time      `bson:"-"`
+	PaymentMethod string         `bson:"-"`
+	PaymentID     *string        `bson:"-"`
+	RefundID      *string        `bson:"-"`
+	RefundAmount  *Money         `bson:"-"`
+	RefundedAt    time.Time      `bson:"-"`
+	ReleasedAt    time.Time      `bson:"-"`
+	PromoCode     *string        `bson:"-"`
+	Discount      *Money         `bson:"-"`
+	PendingRefund *PendingRefund `bson:"-"`
+	Exchanges     []Exchange     `bson:"-"`
+	ExpiresAt     time.Time      `bson:"-"`
+	Version       int64          `bson:"-"`
+	CreatedAt     time.Time      `bson:"-"`
+	UpdatedAt     time.Time      `bson:"-"`
+}
+
+// PendingRefund is money owed back on a ticket that could not be refunded
+// yet, such as the price difference of an exchange to a cheaper seat. Key
+// identifies the refund at the payment provider across retries.
+type PendingRefund struct {
+	Amount Money
+	Key    string
+}
+
+// Exchange records a seat a ticket gave up in ExchangeTicket, oldest
+// first. The ticket held SeatNumber in SessionID at Price from HeldFrom
+// until ExchangedAt, when it had Status and moved to NewSessionID at
+// NewPrice. Reports use it to credit each session with its own share.
+type Exchange struct {
+	SessionID    string
+	SeatNumber   string
+	Price        Money
+	Status       TicketStatus
+	HeldFrom     time.Time
+	ExchangedAt  time.Time
+	NewSessionID string
+	NewPrice     Money
 }
 
 type TicketFilter struct {
@@ -34,14 +73,103 @@ type TicketFilter struct {
 	SeatNumber    *string
 	Status        *TicketStatus
 	PaymentMethod *string
+	ExpiresBefore *time.Time
+	Version       *int64
+	PendingRefund *bool
+
+	// Search fields. Ranges are inclusive on both ends and either end may
+	// be left open. SeatPrefix only applies together with SessionID.
+	Statuses      []TicketStatus
+	SeatPrefix    *string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	PurchasedFrom *time.Time
+	PurchasedTo   *time.Time
+	MinPrice      *Money
+	MaxPrice      *Money
 }
 
 type TicketUpdateData struct {
 	Status        *TicketStatus
+	SessionID     *string
+	SeatNumber    *string
+	UserID        *string
 	PaymentMethod *string
 	PurchaseTime  *time.Time
 	PaymentID     *string
-	Price         *float64
+	Price         *Money
+	Discount      *Money
+	RefundID      *string
+	RefundAmount  *Money
+	RefundedAt    *time.Time
+	// ReleasedAt is when an unpaid hold was cancelled or expired.
+	ReleasedAt *time.Time
+	// PendingRefund records money still owed; a zero amount clears it.
+	PendingRefund *PendingRefund
+	// Exchange is appended to the ticket's exchange history.
+	Exchange *Exchange
+}
+
+// IsExpired reports whether an unpaid reservation has outlived its hold.
+// Tickets without an expiry timestamp never expire.
+func (t Ticket) IsExpired(now time.Time) bool {
+	return t.Status == TicketStatusReserved && !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt)
+}
+
+// HeldSince returns when the ticket took its current seat: its last
+// exchange, or its reservation if it never moved.
+func (t Ticket) HeldSince() time.Time {
+	if n := len(t.Exchanges); n > 0 {
+		return t.Exchanges[n-1].ExchangedAt
+	}
+
+	return t.CreatedAt
+}
+
+// ValidateSearch checks the search fields of a filter before it is run.
+// An empty but non-nil status set is rejected rather than read as "any
+// status", since it can only come from a caller that filtered every
+// status out.
+func (f TicketFilter) ValidateSearch() error {
+	if f.Statuses != nil && len(f.Statuses) == 0 {
+		return fmt.Errorf("%w: status set is empty", ErrInvalidTicketFilter)
+	}
+
+	for _, status := range f.Statuses {
+		if !status.IsValid() {
+			return fmt.Errorf("%w: unknown status %q", ErrInvalidTicketFilter, status)
+		}
+	}
+
+	if f.SeatPrefix != nil && f.SessionID == nil {
+		return fmt.Errorf("%w: seat prefix requires a session", ErrInvalidTicketFilter)
+	}
+
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedTo.Before(*f.CreatedFrom) {
+		return fmt.Errorf("%w: created range ends before it starts", ErrInvalidTicketFilter)
+	}
+
+	if f.PurchasedFrom != nil && f.PurchasedTo != nil && f.PurchasedTo.Before(*f.PurchasedFrom) {
+		return fmt.Errorf("%w: purchase range ends before it starts", ErrInvalidTicketFilter)
+	}
+
+	for _, price := range []*Money{f.MinPrice, f.MaxPrice} {
+		if price != nil && price.IsNegative() {
+			return fmt.Errorf("%w: price bound cannot be negative", ErrInvalidTicketFilter)
+		}
+	}
+
+	if f.MinPrice != nil && f.MaxPrice != nil {
+		if f.MinPrice.Currency != f.MaxPrice.Currency {
+			return fmt.Errorf("%w: price bounds use different currencies", ErrCurrencyMismatch)
+		}
+
+		if f.MaxPrice.Amount < f.MinPrice.Amount {
+			return fmt.Errorf("%w: price range ends before it starts", ErrInvalidTicketFilter)
+		}
+	}
+
+	return nil
 }
 
 // Helpers for creating pointers
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "PENDING"
+	TransferStatusAccepted  TransferStatus = "ACCEPTED"
+	TransferStatusCancelled TransferStatus = "CANCELLED"
+)
+
+// TicketTransfer is an offer by a ticket's owner to hand it to another user.
+// The ticket changes hands only once the recipient accepts.
+type TicketTransfer struct {
+	ID         string
+	TicketID   string
+	FromUserID string
+	ToUserID   string
+	Status     TransferStatus
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,217 @@
+package payment
+
+import (
+	"ap2final_ticket_service/internal/config"
+	"ap2final_ticket_service/internal/models"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	chargesPath = "/v1/charges"
+	refundsPath = "/v1/refunds"
+)
+
+// Decline codes returned by the gateway in the error body.
+const (
+	declineInsufficientFunds    = "insufficient_funds"
+	declineInvalidPaymentMethod = "invalid_payment_method"
+)
+
+// Amounts are sent in minor units of the currency.
+type chargeRequest struct {
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Method    string `json:"method"`
+	Reference string `json:"reference"`
+}
+
+type chargeResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type refundRequest struct {
+	PaymentID string `json:"payment_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+}
+
+type refundResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// transientError marks a failure worth retrying with the same idempotency key.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+type httpPaymentService struct {
+	client       *http.Client
+	baseURL      string
+	apiKey       string
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+func NewHTTPPaymentService(cfg config.PaymentHTTP) Service {
+	return &httpPaymentService{
+		client:       &http.Client{},
+		baseURL:      cfg.BaseURL,
+		apiKey:       cfg.APIKey,
+		timeout:      cfg.Timeout,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+	}
+}
+
+func (s *httpPaymentService) ProcessPayment(ctx context.Context, req PaymentRequest) (PaymentResponse, error) {
+	var res chargeResponse
+
+	err := s.post(ctx, chargesPath, chargeRequest{
+		Amount:    req.Amount.Amount,
+		Currency:  req.Amount.Currency,
+		Method:    req.Method,
+		Reference: req.Reference,
+	}, req.IdempotencyKey, &res, models.ErrPaymentFailed)
+	if err != nil {
+		return PaymentResponse{}, err
+	}
+
+	return PaymentResponse{PaymentID: res.ID}, nil
+}
+
+func (s *httpPaymentService) Refund(ctx context.Context, req RefundRequest) (RefundResponse, error) {
+	var res refundResponse
+
+	err := s.post(ctx, refundsPath, refundRequest{
+		PaymentID: req.PaymentID,
+		Amount:    req.Amount.Amount,
+		Currency:  req.Amount.Currency,
+		Reference: req.Reference,
+	}, req.IdempotencyKey, &res, models.ErrRefundFailed)
+	if err != nil {
+		return RefundResponse{}, err
+	}
+
+	return RefundResponse{RefundID: res.ID}, nil
+}
+
+// post sends payload to the gateway, retrying transient failures, and
+// decodes a successful answer into out. Failures are reported as failErr
+// unless the gateway returned a decline code we map more precisely.
+func (s *httpPaymentService) post(ctx context.Context, path string, payload any, idempotencyKey string, out any, failErr error) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("payment: marshal request: %w", err)
+	}
+
+	// Every retry reuses the key so the gateway applies the operation at most once.
+	if idempotencyKey == "" {
+		idempotencyKey, err = newIdempotencyKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := s.retryBackoff * time.Duration(1<<(attempt-1))
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %v", failErr, ctx.Err())
+			case <-time.After(backoff):
+			}
+		}
+
+		err := s.send(ctx, path, body, idempotencyKey, out, failErr)
+		if err == nil {
+			return nil
+		}
+
+		var transient *transientError
+		if !errors.As(err, &transient) {
+			return err
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("%w: giving up after %d attempts: %v", failErr, s.maxRetries+1, lastErr)
+}
+
+func (s *httpPaymentService) send(ctx context.Context, path string, body []byte, idempotencyKey string, out any, failErr error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("payment: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+	if s.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	httpRes, err := s.client.Do(httpReq)
+	if err != nil {
+		return &transientError{err: fmt.Errorf("payment: send request: %w", err)}
+	}
+	defer httpRes.Body.Close()
+
+	switch {
+	case httpRes.StatusCode == http.StatusOK || httpRes.StatusCode == http.StatusCreated:
+		if err := json.NewDecoder(httpRes.Body).Decode(out); err != nil {
+			return &transientError{err: fmt.Errorf("payment: decode response: %w", err)}
+		}
+
+		return nil
+	case httpRes.StatusCode == http.StatusTooManyRequests || httpRes.StatusCode >= http.StatusInternalServerError:
+		return &transientError{err: fmt.Errorf("payment: gateway returned %d", httpRes.StatusCode)}
+	default:
+		var res errorResponse
+		_ = json.NewDecoder(httpRes.Body).Decode(&res)
+
+		return declineError(res, failErr)
+	}
+}
+
+func declineError(res errorResponse, failErr error) error {
+	switch res.Code {
+	case declineInsufficientFunds:
+		return models.ErrInsufficientFunds
+	case declineInvalidPaymentMethod:
+		return models.ErrInvalidPaymentMethod
+	default:
+		return fmt.Errorf("%w: declined with code %q: %s", failErr, res.Code, res.Message)
+	}
+}
+
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("payment: generate idempotency key: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
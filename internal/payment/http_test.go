@@ -0,0 +1,175 @@
+package payment
+
+import (
+	"ap2final_ticket_service/internal/config"
+	"ap2final_ticket_service/internal/models"
+	"ap2final_ticket_service/internal/payment/paymenttest"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testMaxRetries = 2
+
+func newTestService(t *testing.T, outcomes ...paymenttest.Outcome) (Service, *paymenttest.Gateway) {
+	t.Helper()
+
+	gateway := paymenttest.NewGateway()
+	t.Cleanup(gateway.Close)
+	gateway.Script(outcomes...)
+
+	return NewHTTPPaymentService(config.PaymentHTTP{
+		BaseURL:      gateway.URL(),
+		Timeout:      100 * time.Millisecond,
+		MaxRetries:   testMaxRetries,
+		RetryBackoff: time.Millisecond,
+	}), gateway
+}
+
+func testCharge(key string) PaymentRequest {
+	return PaymentRequest{
+		Amount:         models.NewMoney(2500, "KZT"),
+		Method:         "card",
+		Reference:      "ticket-1",
+		IdempotencyKey: key,
+	}
+}
+
+func TestProcessPayment(t *testing.T) {
+	tests := []struct {
+		name         string
+		outcomes     []paymenttest.Outcome
+		wantErr      error
+		wantRequests int
+		wantCharges  int
+	}{
+		{
+			name:         "approved",
+			wantRequests: 1,
+			wantCharges:  1,
+		},
+		{
+			name:         "insufficient funds",
+			outcomes:     []paymenttest.Outcome{paymenttest.Decline("insufficient_funds")},
+			wantErr:      models.ErrInsufficientFunds,
+			wantRequests: 1,
+		},
+		{
+			name:         "invalid payment method",
+			outcomes:     []paymenttest.Outcome{paymenttest.Decline("invalid_payment_method")},
+			wantErr:      models.ErrInvalidPaymentMethod,
+			wantRequests: 1,
+		},
+		{
+			name:         "other decline",
+			outcomes:     []paymenttest.Outcome{paymenttest.Decline("card_blocked")},
+			wantErr:      models.ErrPaymentFailed,
+			wantRequests: 1,
+		},
+		{
+			name:         "retried after outage",
+			outcomes:     []paymenttest.Outcome{paymenttest.Fail(), paymenttest.Fail()},
+			wantRequests: 3,
+			wantCharges:  1,
+		},
+		{
+			name:         "retried after timeout",
+			outcomes:     []paymenttest.Outcome{paymenttest.Timeout(300 * time.Millisecond)},
+			wantRequests: 2,
+			wantCharges:  1,
+		},
+		{
+			name:         "gives up after retries",
+			outcomes:     []paymenttest.Outcome{paymenttest.Fail(), paymenttest.Fail(), paymenttest.Fail()},
+			wantErr:      models.ErrPaymentFailed,
+			wantRequests: testMaxRetries + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, gateway := newTestService(t, tt.outcomes...)
+
+			res, err := svc.ProcessPayment(context.Background(), testCharge("charge-1"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessPayment() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.PaymentID == "" {
+				t.Errorf("ProcessPayment() returned an empty payment ID")
+			}
+
+			if got := gateway.Requests(); got != tt.wantRequests {
+				t.Errorf("gateway saw %d requests, want %d", got, tt.wantRequests)
+			}
+			if got := gateway.Charges(); got != tt.wantCharges {
+				t.Errorf("gateway approved %d charges, want %d", got, tt.wantCharges)
+			}
+		})
+	}
+}
+
+func TestProcessPaymentIdempotent(t *testing.T) {
+	svc, gateway := newTestService(t)
+	ctx := context.Background()
+
+	first, err := svc.ProcessPayment(ctx, testCharge("charge-1"))
+	if err != nil {
+		t.Fatalf("ProcessPayment() error = %v", err)
+	}
+
+	second, err := svc.ProcessPayment(ctx, testCharge("charge-1"))
+	if err != nil {
+		t.Fatalf("repeated ProcessPayment() error = %v", err)
+	}
+
+	if second.PaymentID != first.PaymentID {
+		t.Errorf("repeated charge got ID %q, want %q", second.PaymentID, first.PaymentID)
+	}
+	if got := gateway.Charges(); got != 1 {
+		t.Errorf("gateway approved %d charges, want 1", got)
+	}
+
+	if _, err := svc.ProcessPayment(ctx, testCharge("charge-2")); err != nil {
+		t.Fatalf("ProcessPayment() with a new key error = %v", err)
+	}
+	if got := gateway.Charges(); got != 2 {
+		t.Errorf("gateway approved %d charges, want 2", got)
+	}
+}
+
+func TestRefund(t *testing.T) {
+	tests := []struct {
+		name        string
+		outcomes    []paymenttest.Outcome
+		wantErr     error
+		wantRefunds int
+	}{
+		{name: "approved", wantRefunds: 1},
+		{name: "retried after outage", outcomes: []paymenttest.Outcome{paymenttest.Fail()}, wantRefunds: 1},
+		{name: "declined", outcomes: []paymenttest.Outcome{paymenttest.Decline("charge_disputed")}, wantErr: models.ErrRefundFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, gateway := newTestService(t, tt.outcomes...)
+
+			res, err := svc.Refund(context.Background(), RefundRequest{
+				PaymentID:      "fake-pay-1",
+				Amount:         models.NewMoney(1000, "KZT"),
+				Reference:      "ticket-1",
+				IdempotencyKey: "refund-1",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refund() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.RefundID == "" {
+				t.Errorf("Refund() returned an empty refund ID")
+			}
+
+			if got := gateway.Refunds(); got != tt.wantRefunds {
+				t.Errorf("gateway approved %d refunds, want %d", got, tt.wantRefunds)
+			}
+		})
+	}
+}
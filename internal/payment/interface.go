@@ -1,10 +1,28 @@
 package payment
 
+import "ap2final_ticket_service/internal/models"
+
 type PaymentRequest struct {
-	Amount   float64
-	Currency string
+	Amount models.Money
+	Method string
+	// Reference identifies what is being paid for, e.g. the ticket ID.
+	Reference string
+	// IdempotencyKey makes repeating the same request a no-op at the
+	// provider. A random key is used when it is empty.
+	IdempotencyKey string
 }
 
 type PaymentResponse struct {
 	PaymentID string
 }
+
+type RefundRequest struct {
+	PaymentID      string
+	Amount         models.Money
+	Reference      string
+	IdempotencyKey string
+}
+
+type RefundResponse struct {
+	RefundID string
+}
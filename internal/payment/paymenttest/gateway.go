@@ -0,0 +1,159 @@
+// Package paymenttest provides a local stand-in for the HTTP payment
+// gateway. Each charge or refund consumes the next scripted outcome; once
+// the script runs out every request is approved.
+package paymenttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+type outcomeKind int
+
+const (
+	outcomeApprove outcomeKind = iota
+	outcomeDecline
+	outcomeFail
+	outcomeTimeout
+)
+
+type Outcome struct {
+	kind  outcomeKind
+	code  string
+	delay time.Duration
+}
+
+// Approve accepts the charge or refund.
+func Approve() Outcome { return Outcome{kind: outcomeApprove} }
+
+// Decline rejects the request with the given decline code, e.g. "insufficient_funds".
+func Decline(code string) Outcome { return Outcome{kind: outcomeDecline, code: code} }
+
+// Fail answers with 503 Service Unavailable, which clients should retry.
+func Fail() Outcome { return Outcome{kind: outcomeFail} }
+
+// Timeout holds the request for d (or until the client gives up) before approving it.
+func Timeout(d time.Duration) Outcome { return Outcome{kind: outcomeTimeout, delay: d} }
+
+type Gateway struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	script   []Outcome
+	results  map[string]string // idempotency key -> charge or refund ID
+	requests int
+	charges  int
+	refunds  int
+}
+
+func NewGateway() *Gateway {
+	g := &Gateway{results: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/charges", g.handle("pay", &g.charges))
+	mux.HandleFunc("POST /v1/refunds", g.handle("refund", &g.refunds))
+	g.srv = httptest.NewServer(mux)
+
+	return g
+}
+
+func (g *Gateway) URL() string {
+	return g.srv.URL
+}
+
+func (g *Gateway) Close() {
+	g.srv.Close()
+}
+
+// Script queues outcomes for the next requests, charges and refunds alike,
+// in the order they reach the gateway.
+func (g *Gateway) Script(outcomes ...Outcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.script = append(g.script, outcomes...)
+}
+
+// Requests returns how many requests reached the gateway, retries included.
+func (g *Gateway) Requests() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.requests
+}
+
+// Charges returns how many distinct charges were approved.
+func (g *Gateway) Charges() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.charges
+}
+
+// Refunds returns how many distinct refunds were approved.
+func (g *Gateway) Refunds() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.refunds
+}
+
+func (g *Gateway) handle(prefix string, approved *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g.serve(w, r, prefix, approved)
+	}
+}
+
+func (g *Gateway) serve(w http.ResponseWriter, r *http.Request, prefix string, approved *int) {
+	key := r.Header.Get("Idempotency-Key")
+
+	g.mu.Lock()
+	g.requests++
+	if id, ok := g.results[key]; ok && key != "" {
+		g.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"id": id, "status": "approved"})
+		return
+	}
+
+	outcome := Approve()
+	if len(g.script) > 0 {
+		outcome = g.script[0]
+		g.script = g.script[1:]
+	}
+	g.mu.Unlock()
+
+	switch outcome.kind {
+	case outcomeDecline:
+		writeJSON(w, http.StatusPaymentRequired, map[string]string{"code": outcome.code, "message": prefix + " declined"})
+		return
+	case outcomeFail:
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"code": "unavailable", "message": "try again"})
+		return
+	case outcomeTimeout:
+		select {
+		case <-time.After(outcome.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	g.mu.Lock()
+	*approved++
+	id := fmt.Sprintf("fake-%s-%d", prefix, *approved)
+	if key != "" {
+		g.results[key] = id
+	}
+	g.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id, "status": "approved"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
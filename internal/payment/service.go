@@ -1,12 +1,17 @@
 package payment
 
 import (
+	"ap2final_ticket_service/internal/models"
 	"context"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Service charges customers through a payment provider. Declines are
+// reported as models.ErrInsufficientFunds, models.ErrInvalidPaymentMethod
+// or models.ErrPaymentFailed; failed refunds as models.ErrRefundFailed.
 type Service interface {
-	ProcessPayment(ctx context.Context, amount float64, currency string) (string, error)
+	ProcessPayment(ctx context.Context, req PaymentRequest) (PaymentResponse, error)
+	Refund(ctx context.Context, req RefundRequest) (RefundResponse, error)
 }
 
 type mockPaymentService struct{}
@@ -15,7 +20,24 @@ func NewMockPaymentService() Service {
 	return &mockPaymentService{}
 }
 
-func (s *mockPaymentService) ProcessPayment(ctx context.Context, amount float64, currency string) (string, error) {
+func (s *mockPaymentService) ProcessPayment(ctx context.Context, req PaymentRequest) (PaymentResponse, error) {
+	if req.Method == "" {
+		return PaymentResponse{}, models.ErrInvalidPaymentMethod
+	}
+
+	if req.Amount.IsNegative() {
+		return PaymentResponse{}, models.ErrPaymentFailed
+	}
+
+	objectID := primitive.NewObjectID()
+	return PaymentResponse{PaymentID: "mock-pay-" + objectID.Hex()}, nil
+}
+
+func (s *mockPaymentService) Refund(ctx context.Context, req RefundRequest) (RefundResponse, error) {
+	if req.PaymentID == "" {
+		return RefundResponse{}, models.ErrRefundFailed
+	}
+
 	objectID := primitive.NewObjectID()
-	return "mock-pay-" + objectID.Hex(), nil
+	return RefundResponse{RefundID: "mock-refund-" + objectID.Hex()}, nil
 }
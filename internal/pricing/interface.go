@@ -0,0 +1,21 @@
+package pricing
+
+import (
+	"ap2final_ticket_service/internal/models"
+	"context"
+)
+
+// Engine computes what a seat costs. The use case charges this price
+// instead of trusting one sent by the client.
+type Engine interface {
+	Price(ctx context.Context, req PriceRequest) (models.Money, error)
+}
+
+type PriceRequest struct {
+	SessionID  string
+	SeatNumber string
+	// Session and Hall are nil when the schedule or layout does not know
+	// the session; rules that need them are then skipped.
+	Session *models.Session
+	Hall    *models.Hall
+}
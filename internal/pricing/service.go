@@ -0,0 +1,194 @@
+package pricing
+
+import (
+	"ap2final_ticket_service/internal/config"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Multipliers are kept in basis points so prices stay exact integers.
+const basisPoints = 10000
+
+type seatCategory struct {
+	rows       map[string]struct{}
+	seats      map[string]struct{}
+	multiplier int64
+}
+
+type timeOfDayRule struct {
+	from, to   int // minutes since midnight
+	multiplier int64
+}
+
+type ruleEngine struct {
+	basePrice  models.Money
+	location   *time.Location
+	weekend    int64
+	categories []seatCategory
+	timeOfDay  []timeOfDayRule
+}
+
+func NewRuleEngine(cfg config.Pricing, currency string) (Engine, error) {
+	const op = "pricing.NewRuleEngine"
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	weekend, err := toBasisPoints(cfg.WeekendMultiplier)
+	if err != nil {
+		return nil, fmt.Errorf("%s: weekend: %w", op, err)
+	}
+
+	e := &ruleEngine{
+		basePrice: models.MoneyFromFloat(cfg.BasePrice, currency),
+		location:  location,
+		weekend:   weekend,
+	}
+
+	for _, c := range cfg.SeatCategories {
+		multiplier, err := toBasisPoints(c.Multiplier)
+		if err != nil {
+			return nil, fmt.Errorf("%s: seat category %s: %w", op, c.Name, err)
+		}
+
+		category := seatCategory{
+			rows:       make(map[string]struct{}, len(c.Rows)),
+			seats:      make(map[string]struct{}, len(c.Seats)),
+			multiplier: multiplier,
+		}
+		for _, row := range c.Rows {
+			category.rows[row] = struct{}{}
+		}
+		for _, seat := range c.Seats {
+			category.seats[seat] = struct{}{}
+		}
+
+		e.categories = append(e.categories, category)
+	}
+
+	for _, r := range cfg.TimeOfDay {
+		from, err := parseClock(r.From)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		to, err := parseClock(r.To)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		multiplier, err := toBasisPoints(r.Multiplier)
+		if err != nil {
+			return nil, fmt.Errorf("%s: time of day %s-%s: %w", op, r.From, r.To, err)
+		}
+
+		e.timeOfDay = append(e.timeOfDay, timeOfDayRule{from: from, to: to, multiplier: multiplier})
+	}
+
+	return e, nil
+}
+
+func (e *ruleEngine) Price(ctx context.Context, req PriceRequest) (models.Money, error) {
+	price := e.basePrice
+	if req.Session != nil && req.Session.BasePrice != nil {
+		price = *req.Session.BasePrice
+	}
+
+	if price.Currency != e.basePrice.Currency {
+		return models.Money{}, fmt.Errorf("%w: session %s is priced in %s", models.ErrCurrencyMismatch, req.SessionID, price.Currency)
+	}
+
+	row := rowOf(req.Hall, req.SeatNumber)
+	for _, category := range e.categories {
+		if category.matches(row, req.SeatNumber) {
+			price.Amount = scale(price.Amount, category.multiplier)
+			break
+		}
+	}
+
+	if req.Session != nil && !req.Session.StartsAt.IsZero() {
+		startsAt := req.Session.StartsAt.In(e.location)
+
+		minute := startsAt.Hour()*60 + startsAt.Minute()
+		for _, rule := range e.timeOfDay {
+			if rule.matches(minute) {
+				price.Amount = scale(price.Amount, rule.multiplier)
+				break
+			}
+		}
+
+		if weekday := startsAt.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			price.Amount = scale(price.Amount, e.weekend)
+		}
+	}
+
+	return price, nil
+}
+
+func (c seatCategory) matches(row, seatNumber string) bool {
+	if _, ok := c.seats[seatNumber]; ok {
+		return true
+	}
+
+	_, ok := c.rows[row]
+	return ok
+}
+
+func (r timeOfDayRule) matches(minute int) bool {
+	if r.from <= r.to {
+		return minute >= r.from && minute < r.to
+	}
+
+	// The window wraps past midnight.
+	return minute >= r.from || minute < r.to
+}
+
+// rowOf finds the row label of seatNumber in the hall layout, or falls
+// back to the seat number without its trailing digits.
+func rowOf(hall *models.Hall, seatNumber string) string {
+	if hall != nil {
+		for _, row := range hall.Rows {
+			for _, seat := range row.SeatNumbers {
+				if seat == seatNumber {
+					return row.Label
+				}
+			}
+		}
+	}
+
+	return strings.TrimRightFunc(seatNumber, unicode.IsDigit)
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", value, err)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func toBasisPoints(multiplier float64) (int64, error) {
+	if multiplier <= 0 {
+		return 0, fmt.Errorf("invalid price multiplier %v", multiplier)
+	}
+
+	return int64(math.Round(multiplier * basisPoints)), nil
+}
+
+// scale multiplies amount by bp basis points, rounding half away from zero.
+func scale(amount, bp int64) int64 {
+	product := amount * bp
+	if product < 0 {
+		return -((-product + basisPoints/2) / basisPoints)
+	}
+
+	return (product + basisPoints/2) / basisPoints
+}
@@ -0,0 +1,150 @@
+package pricing
+
+import (
+	"ap2final_ticket_service/internal/config"
+	"ap2final_ticket_service/internal/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testPricing() config.Pricing {
+	return config.Pricing{
+		BasePrice:         20,
+		Timezone:          "UTC",
+		WeekendMultiplier: 1.1,
+		SeatCategories: []config.SeatCategory{
+			{Name: "vip", Rows: []string{"A"}, Multiplier: 1.5},
+			{Name: "sofa", Seats: []string{"A1", "B1"}, Multiplier: 2},
+		},
+		TimeOfDay: []config.TimeOfDayRule{
+			{From: "22:00", To: "02:00", Multiplier: 0.8},
+			{From: "18:00", To: "23:00", Multiplier: 1.2},
+		},
+	}
+}
+
+func TestRuleEnginePrice(t *testing.T) {
+	engine, err := NewRuleEngine(testPricing(), "KZT")
+	if err != nil {
+		t.Fatalf("NewRuleEngine() error = %v", err)
+	}
+
+	// 2 March 2026 is a Monday, 7 March a Saturday.
+	monday := func(hour, minute int) *models.Session {
+		return &models.Session{StartsAt: time.Date(2026, 3, 2, hour, minute, 0, 0, time.UTC)}
+	}
+	saturday := func(hour, minute int) *models.Session {
+		return &models.Session{StartsAt: time.Date(2026, 3, 7, hour, minute, 0, 0, time.UTC)}
+	}
+
+	tests := []struct {
+		name    string
+		seat    string
+		session *models.Session
+		hall    *models.Hall
+		want    int64
+	}{
+		{name: "no rule applies", seat: "C5", session: monday(12, 0), want: 2000},
+		{name: "row category", seat: "A5", session: monday(12, 0), want: 3000},
+		{name: "first matching category wins", seat: "A1", session: monday(12, 0), want: 3000},
+		{name: "seat category", seat: "B1", session: monday(12, 0), want: 4000},
+		{
+			name:    "row taken from the hall layout",
+			seat:    "S1",
+			session: monday(12, 0),
+			hall:    &models.Hall{Rows: []models.HallRow{{Label: "A", SeatNumbers: []string{"S1"}}}},
+			want:    3000,
+		},
+		{name: "window wrapping past midnight", seat: "C5", session: monday(1, 0), want: 1600},
+		{name: "first matching window wins", seat: "C5", session: monday(22, 30), want: 1600},
+		{name: "window start is inclusive", seat: "C5", session: monday(18, 0), want: 2400},
+		{name: "window end is exclusive", seat: "C5", session: monday(2, 0), want: 2000},
+		{name: "weekend", seat: "C5", session: saturday(12, 0), want: 2200},
+		{name: "category, window and weekend stack", seat: "A5", session: saturday(18, 0), want: 3960},
+		{
+			// 2005 × 1.5 = 3007.5 rounds up to 3008, and 3008 × 1.1 = 3308.8 to 3309.
+			name:    "each step rounds half away from zero",
+			seat:    "A5",
+			session: &models.Session{StartsAt: saturday(12, 0).StartsAt, BasePrice: models.NewMoney(2005, "KZT").Ptr()},
+			want:    3309,
+		},
+		{name: "unknown session skips time rules", seat: "A5", want: 3000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Price(context.Background(), PriceRequest{
+				SessionID:  "s1",
+				SeatNumber: tt.seat,
+				Session:    tt.session,
+				Hall:       tt.hall,
+			})
+			if err != nil {
+				t.Fatalf("Price() error = %v", err)
+			}
+
+			if want := models.NewMoney(tt.want, "KZT"); got != want {
+				t.Errorf("Price() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRuleEnginePriceCurrencyMismatch(t *testing.T) {
+	engine, err := NewRuleEngine(testPricing(), "KZT")
+	if err != nil {
+		t.Fatalf("NewRuleEngine() error = %v", err)
+	}
+
+	_, err = engine.Price(context.Background(), PriceRequest{
+		SessionID:  "s1",
+		SeatNumber: "C5",
+		Session:    &models.Session{BasePrice: models.NewMoney(1500, "USD").Ptr()},
+	})
+	if !errors.Is(err, models.ErrCurrencyMismatch) {
+		t.Errorf("Price() error = %v, want %v", err, models.ErrCurrencyMismatch)
+	}
+}
+
+func TestNewRuleEngineRejectsBadRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*config.Pricing)
+	}{
+		{name: "unknown timezone", modify: func(p *config.Pricing) { p.Timezone = "Nowhere/City" }},
+		{name: "zero weekend multiplier", modify: func(p *config.Pricing) { p.WeekendMultiplier = 0 }},
+		{name: "negative category multiplier", modify: func(p *config.Pricing) { p.SeatCategories[0].Multiplier = -1 }},
+		{name: "malformed clock", modify: func(p *config.Pricing) { p.TimeOfDay[0].From = "25:00" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPricing()
+			tt.modify(&cfg)
+
+			if _, err := NewRuleEngine(cfg, "KZT"); err == nil {
+				t.Errorf("NewRuleEngine() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		amount, bp, want int64
+	}{
+		{amount: 1000, bp: basisPoints, want: 1000},
+		{amount: 1001, bp: 5000, want: 501},
+		{amount: 1003, bp: 5000, want: 502},
+		{amount: 999, bp: 3333, want: 333},
+		{amount: -1001, bp: 5000, want: -501},
+	}
+
+	for _, tt := range tests {
+		if got := scale(tt.amount, tt.bp); got != tt.want {
+			t.Errorf("scale(%d, %d) = %d, want %d", tt.amount, tt.bp, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+)
+
+// ExchangeTicket moves a live ticket to newSeatNumber in newSessionID, a
+// showtime of the same movie, as long as neither showtime has started. The
+// ticket keeps its ID, status and payment; it is repriced for the new seat,
+// keeping the promo code it was reserved with, and for paid tickets the
+// difference is charged or refunded. A refund the provider fails to pay is
+// kept on the ticket and retried. The seat given up is recorded in the
+// ticket's exchange history. Moving the ticket document frees the old seat
+// and claims the new one in a single write, so both happen or neither does.
+func (uc *ticketUseCase) ExchangeTicket(
+	ctx context.Context,
+	ticketID, newSessionID, newSeatNumber string,
+) (*models.Ticket, error) {
+	// Money may move below, so read the stored ticket rather than a cached copy.
+	existing, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := existing.Status.EnsureActive(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if existing.IsExpired(now) {
+		return nil, models.ErrTicketExpired
+	}
+
+	// Settle what an earlier exchange still owes before pricing another.
+	if existing.PendingRefund != nil {
+		existing, err = uc.settleRefund(ctx, existing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if newSessionID == existing.SessionID && newSeatNumber == existing.SeatNumber {
+		return nil, fmt.Errorf("%w: ticket already holds this seat", models.ErrInvalidTicketData)
+	}
+
+	if _, err := uc.upcomingSession(ctx, existing.SessionID, now); err != nil {
+		return nil, err
+	}
+
+	session, err := uc.upcomingSession(ctx, newSessionID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.MovieID != "" && session.MovieID != existing.MovieID {
+		return nil, fmt.Errorf("%w: tickets can only be exchanged for the same movie", models.ErrInvalidTicketData)
+	}
+
+	hall, err := uc.sessionHall(ctx, newSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hall != nil {
+		if !hall.HasSeat(newSeatNumber) {
+			return nil, models.ErrInvalidSeatNumber
+		}
+
+		if newSessionID != existing.SessionID {
+			if err := uc.checkCapacity(ctx, newSessionID, *hall, 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	promo, err := uc.exchangePromo(ctx, existing, newSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	newPrice, discount, err := uc.priceSeat(ctx, newSessionID, newSeatNumber, &session, hall, promo)
+	if err != nil {
+		return nil, err
+	}
+
+	difference, err := newPrice.Sub(existing.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := uc.locker.AcquireSeatLock(ctx, newSessionID, newSeatNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer uc.releaseSeatLock(ctx, newSessionID, newSeatNumber, token)
+
+	available, err := uc.repo.IsSeatAvailable(ctx, newSessionID, newSeatNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		_ = uc.cache.InvalidateSeatMap(ctx, newSessionID)
+		return nil, models.ErrSeatAlreadyTaken
+	}
+
+	paid := existing.Status == models.TicketStatusPaid
+
+	// Charge an upgrade before moving so a declined card leaves the ticket as it was.
+	var surcharge *models.Payment
+	if paid && difference.Amount > 0 {
+		record, err := uc.charge(ctx, chargeExchange, existing, difference, existing.PaymentMethod)
+		if err != nil {
+			return nil, err
+		}
+		surcharge = &record
+	}
+
+	update := models.TicketUpdateData{
+		SessionID:  &newSessionID,
+		SeatNumber: &newSeatNumber,
+		Price:      &newPrice,
+		Discount:   discount,
+		Exchange: &models.Exchange{
+			SessionID:    existing.SessionID,
+			SeatNumber:   existing.SeatNumber,
+			Price:        existing.Price,
+			Status:       existing.Status,
+			HeldFrom:     existing.HeldSince(),
+			ExchangedAt:  now,
+			NewSessionID: newSessionID,
+			NewPrice:     newPrice,
+		},
+	}
+	if paid && difference.Amount < 0 {
+		update.PendingRefund = &models.PendingRefund{
+			Amount: models.Money{Amount: -difference.Amount, Currency: difference.Currency},
+			Key:    fmt.Sprintf("exchange:%s:%d", existing.ID, existing.Version),
+		}
+	}
+	reason := fmt.Sprintf("exchanged from session %s seat %s", existing.SessionID, existing.SeatNumber)
+
+	updatedTicket, err := uc.applyTransition(ctx, existing, update, reason)
+	if err != nil {
+		if surcharge != nil {
+			uc.voidCharge(ctx, existing, *surcharge)
+		}
+
+		if errors.Is(err, models.ErrSeatAlreadyTaken) {
+			_ = uc.cache.InvalidateSeatMap(ctx, newSessionID)
+		}
+
+		return nil, err
+	}
+
+	if updatedTicket.PendingRefund != nil {
+		settled, err := uc.settleRefund(ctx, updatedTicket)
+		if err != nil {
+			uc.log.Warn("price difference not refunded yet, will retry", "ticket_id", ticketID, "error", err)
+		} else {
+			updatedTicket = settled
+		}
+	}
+
+	if err := uc.cache.CacheTicket(ctx, &updatedTicket); err != nil {
+		uc.log.Warn("failed to cache exchanged ticket", "ticket_id", updatedTicket.ID, "error", err)
+	}
+
+	_ = uc.cache.InvalidateUserTickets(ctx, updatedTicket.UserID)
+
+	_ = uc.cache.InvalidateSessionTickets(ctx, existing.SessionID)
+
+	_ = uc.cache.InvalidateSessionTickets(ctx, newSessionID)
+
+	_ = uc.cache.CacheSeatState(ctx, existing.SessionID, existing.SeatNumber, models.SeatStateFree)
+
+	_ = uc.cache.CacheSeatState(ctx, newSessionID, newSeatNumber, models.SeatStateFromTicketStatus(updatedTicket.Status))
+
+	return &updatedTicket, nil
+}
+
+// upcomingSession returns a scheduled session that has not started at now.
+// Sessions missing from the schedule fail with models.ErrSessionNotFound.
+func (uc *ticketUseCase) upcomingSession(ctx context.Context, sessionID string, now time.Time) (models.Session, error) {
+	session, err := uc.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	if session.HasStarted(now) {
+		return models.Session{}, models.ErrShowtimeStarted
+	}
+
+	return session, nil
+}
+
+// exchangePromo returns the promo code ticket was reserved with, checked
+// against the session it moves to. The ticket already holds a redemption,
+// so the code's validity window and usage limits no longer apply.
+func (uc *ticketUseCase) exchangePromo(ctx context.Context, ticket models.Ticket, newSessionID string) (*models.PromoCode, error) {
+	if ticket.PromoCode == nil {
+		return nil, nil
+	}
+
+	promo, err := uc.promos.FindByCode(ctx, *ticket.PromoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if !promo.AppliesTo(ticket.MovieID, newSessionID) {
+		return nil, fmt.Errorf("%w: promo code %s does not cover the new session", models.ErrPromoCodeNotApplicable, promo.Code)
+	}
+
+	return &promo, nil
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ap2final_ticket_service/internal/adapter/cache"
+	"ap2final_ticket_service/internal/models"
+)
+
+func TestExchangeTicket(t *testing.T) {
+	const (
+		movieID   = "665f1c2e8b3e4a0012345679"
+		userID    = "665f1c2e8b3e4a00000000a1"
+		oldID     = "665f1c2e8b3e4a0000000001"
+		laterID   = "665f1c2e8b3e4a0000000002"
+		pastID    = "665f1c2e8b3e4a0000000003"
+		otherID   = "665f1c2e8b3e4a0000000004"
+		unknownID = "665f1c2e8b3e4a0000000005"
+	)
+
+	now := time.Now()
+	sessions := scheduledSessions{
+		oldID:   {ID: oldID, MovieID: movieID, StartsAt: now.Add(24 * time.Hour)},
+		laterID: {ID: laterID, MovieID: movieID, StartsAt: now.Add(48 * time.Hour)},
+		pastID:  {ID: pastID, MovieID: movieID, StartsAt: now.Add(-time.Hour)},
+		otherID: {ID: otherID, MovieID: "665f1c2e8b3e4a0012345670", StartsAt: now.Add(48 * time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		from      string
+		toSession string
+		wantErr   error
+	}{
+		{name: "later showtime", from: oldID, toSession: laterID},
+		{name: "new session not in schedule", from: oldID, toSession: unknownID, wantErr: models.ErrSessionNotFound},
+		{name: "new showtime started", from: oldID, toSession: pastID, wantErr: models.ErrShowtimeStarted},
+		{name: "old showtime started", from: pastID, toSession: laterID, wantErr: models.ErrShowtimeStarted},
+		{name: "old session not in schedule", from: unknownID, toSession: laterID, wantErr: models.ErrSessionNotFound},
+		{name: "another movie", from: oldID, toSession: otherID, wantErr: models.ErrInvalidTicketData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTicketRepo{uniqueSeats: true}
+			uc := newTestUseCase(repo, cache.NewMemorySeatLocker(time.Minute))
+			uc.sessions = sessions
+			ctx := context.Background()
+
+			reserved, err := repo.InsertOne(ctx, &models.Ticket{
+				SessionID:  tt.from,
+				MovieID:    movieID,
+				UserID:     userID,
+				SeatNumber: "A1",
+				Price:      models.NewMoney(2500, "KZT"),
+				Status:     models.TicketStatusReserved,
+				ExpiresAt:  now.Add(time.Minute),
+				CreatedAt:  now.Add(-time.Minute),
+			})
+			if err != nil {
+				t.Fatalf("InsertOne() error = %v", err)
+			}
+
+			exchanged, err := uc.ExchangeTicket(ctx, reserved.ID, tt.toSession, "A5")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExchangeTicket() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if exchanged.SessionID != tt.toSession || exchanged.SeatNumber != "A5" {
+				t.Errorf("ticket holds %s/%s, want %s/A5", exchanged.SessionID, exchanged.SeatNumber, tt.toSession)
+			}
+
+			if len(exchanged.Exchanges) != 1 {
+				t.Fatalf("ticket records %d exchanges, want 1", len(exchanged.Exchanges))
+			}
+
+			got := exchanged.Exchanges[0]
+			if got.SessionID != tt.from || got.SeatNumber != "A1" || got.NewSessionID != tt.toSession {
+				t.Errorf("exchange moved %s/%s to %s, want %s/A1 to %s",
+					got.SessionID, got.SeatNumber, got.NewSessionID, tt.from, tt.toSession)
+			}
+			if got.Status != models.TicketStatusReserved {
+				t.Errorf("exchange status = %s, want %s", got.Status, models.TicketStatusReserved)
+			}
+			if !got.HeldFrom.Equal(reserved.CreatedAt) {
+				t.Errorf("exchange held from %v, want the reservation time %v", got.HeldFrom, reserved.CreatedAt)
+			}
+			if !exchanged.HeldSince().Equal(got.ExchangedAt) {
+				t.Errorf("HeldSince() = %v, want the exchange time %v", exchanged.HeldSince(), got.ExchangedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,554 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"ap2final_ticket_service/internal/adapter/cache"
+	"ap2final_ticket_service/internal/models"
+	"ap2final_ticket_service/internal/payment"
+	"ap2final_ticket_service/internal/pricing"
+)
+
+// memTicketRepo is an in-memory TicketRepository. With uniqueSeats set it
+// rejects a second active ticket for a seat the way the uniq_active_seat
+// index does.
+type memTicketRepo struct {
+	mu          sync.Mutex
+	tickets     []models.Ticket
+	nextID      int
+	uniqueSeats bool
+	lastFilter  *models.TicketFilter
+	lastPage    *models.PageRequest
+	largestPage int
+}
+
+func (r *memTicketRepo) InsertOne(ctx context.Context, ticket *models.Ticket) (models.Ticket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(*ticket)
+}
+
+func (r *memTicketRepo) InsertMany(ctx context.Context, tickets []models.Ticket) ([]models.Ticket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := make([]models.Ticket, len(tickets))
+	for i, ticket := range tickets {
+		var err error
+		if created[i], err = r.insert(ticket); err != nil {
+			return nil, err
+		}
+	}
+
+	return created, nil
+}
+
+func (r *memTicketRepo) insert(ticket models.Ticket) (models.Ticket, error) {
+	if r.uniqueSeats && isActive(ticket.Status) {
+		for _, t := range r.tickets {
+			if t.SessionID == ticket.SessionID && t.SeatNumber == ticket.SeatNumber && isActive(t.Status) {
+				return models.Ticket{}, models.ErrSeatAlreadyTaken
+			}
+		}
+	}
+
+	r.nextID++
+	ticket.ID = fmt.Sprintf("%024x", r.nextID)
+	r.tickets = append(r.tickets, ticket)
+
+	return ticket, nil
+}
+
+func (r *memTicketRepo) FindOne(ctx context.Context, filter models.TicketFilter) (models.Ticket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tickets {
+		if matches(t, filter) {
+			return t, nil
+		}
+	}
+
+	return models.Ticket{}, models.ErrTicketNotFound
+}
+
+func (r *memTicketRepo) Find(ctx context.Context, filter models.TicketFilter, page models.PageRequest) ([]models.Ticket, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastFilter = &filter
+	r.lastPage = &page
+
+	// IDs grow with insertion order, so the last ID on a page is a cursor.
+	var found []models.Ticket
+	for _, t := range r.tickets {
+		if matches(t, filter) && t.ID > page.Cursor {
+			found = append(found, t)
+		}
+	}
+
+	var next string
+	if page.Size > 0 && len(found) > page.Size {
+		found = found[:page.Size]
+		next = found[page.Size-1].ID
+	}
+	r.largestPage = max(r.largestPage, len(found))
+
+	return found, next, nil
+}
+
+func (r *memTicketRepo) Stream(ctx context.Context, filter models.TicketFilter, page models.PageRequest, fn func(models.Ticket) error) error {
+	found, _, err := r.Find(ctx, filter, page)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range found {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *memTicketRepo) UpdateOne(ctx context.Context, filter models.TicketFilter, update models.TicketUpdateData) (models.Ticket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, t := range r.tickets {
+		if !matches(t, filter) {
+			continue
+		}
+
+		if update.Status != nil {
+			t.Status = *update.Status
+		}
+		if update.SessionID != nil {
+			t.SessionID = *update.SessionID
+		}
+		if update.SeatNumber != nil {
+			t.SeatNumber = *update.SeatNumber
+		}
+		if update.Price != nil {
+			t.Price = *update.Price
+		}
+		if update.PaymentMethod != nil {
+			t.PaymentMethod = *update.PaymentMethod
+		}
+		if update.PaymentID != nil {
+			t.PaymentID = update.PaymentID
+		}
+		if update.UserID != nil {
+			t.UserID = *update.UserID
+		}
+		if update.Exchange != nil {
+			t.Exchanges = append(slices.Clone(t.Exchanges), *update.Exchange)
+		}
+		t.Version++
+		t.UpdatedAt = time.Now()
+
+		r.tickets[i] = t
+		return t, nil
+	}
+
+	if filter.ID != nil && filter.Version != nil {
+		return models.Ticket{}, models.ErrTicketVersionConflict
+	}
+
+	return models.Ticket{}, models.ErrTicketNotFound
+}
+
+func (r *memTicketRepo) IsSeatAvailable(ctx context.Context, sessionID, seatNumber string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tickets {
+		if t.SessionID == sessionID && t.SeatNumber == seatNumber && isActive(t.Status) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (r *memTicketRepo) FindSeatStates(ctx context.Context, sessionID string) (map[string]models.SeatState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seats := make(map[string]models.SeatState)
+	for _, t := range r.tickets {
+		if t.SessionID == sessionID && isActive(t.Status) {
+			seats[t.SeatNumber] = models.SeatStateFromTicketStatus(t.Status)
+		}
+	}
+
+	return seats, nil
+}
+
+func (r *memTicketRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// active returns the tickets holding seatNumber in sessionID.
+func (r *memTicketRepo) active(sessionID, seatNumber string) []models.Ticket {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var held []models.Ticket
+	for _, t := range r.tickets {
+		if t.SessionID == sessionID && t.SeatNumber == seatNumber && isActive(t.Status) {
+			held = append(held, t)
+		}
+	}
+
+	return held
+}
+
+func isActive(status models.TicketStatus) bool {
+	return status == models.TicketStatusReserved || status == models.TicketStatusPaid
+}
+
+func matches(t models.Ticket, f models.TicketFilter) bool {
+	switch {
+	case f.ID != nil && t.ID != *f.ID:
+		return false
+	case f.Version != nil && t.Version != *f.Version:
+		return false
+	case f.SessionID != nil && t.SessionID != *f.SessionID:
+		return false
+	case f.UserID != nil && t.UserID != *f.UserID:
+		return false
+	case f.MovieID != nil && t.MovieID != *f.MovieID:
+		return false
+	case f.Status != nil && t.Status != *f.Status:
+		return false
+	case len(f.Statuses) > 0 && !slices.Contains(f.Statuses, t.Status):
+		return false
+	case f.ExpiresBefore != nil && !t.ExpiresAt.Before(*f.ExpiresBefore):
+		return false
+	}
+
+	return true
+}
+
+// memPaymentRepo is an in-memory PaymentRepository that keeps claim keys
+// unique among pending and succeeded payments like the claim_key index.
+type memPaymentRepo struct {
+	mu       sync.Mutex
+	payments []models.Payment
+	nextID   int
+}
+
+func (r *memPaymentRepo) InsertOne(ctx context.Context, payment models.Payment) (models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.payments {
+		if payment.ClaimKey != "" && p.ClaimKey == payment.ClaimKey && holdsClaim(p.Status) {
+			return models.Payment{}, models.ErrPaymentInProgress
+		}
+	}
+
+	r.nextID++
+	payment.ID = fmt.Sprintf("%024x", r.nextID)
+	r.payments = append(r.payments, payment)
+
+	return payment, nil
+}
+
+func (r *memPaymentRepo) FindOne(ctx context.Context, filter models.PaymentFilter) (models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.payments {
+		if matchesPayment(p, filter) {
+			return p, nil
+		}
+	}
+
+	return models.Payment{}, models.ErrPaymentNotFound
+}
+
+func (r *memPaymentRepo) Find(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []models.Payment
+	for _, p := range r.payments {
+		if matchesPayment(p, filter) {
+			found = append(found, p)
+		}
+	}
+
+	return found, nil
+}
+
+func (r *memPaymentRepo) UpdateOne(ctx context.Context, filter models.PaymentFilter, update models.PaymentUpdateData) (models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, p := range r.payments {
+		if !matchesPayment(p, filter) {
+			continue
+		}
+
+		if update.Status != nil {
+			p.Status = *update.Status
+		}
+		if update.ProviderRef != nil {
+			p.ProviderRef = *update.ProviderRef
+		}
+		if update.ClaimedAt != nil {
+			p.ClaimedAt = *update.ClaimedAt
+		}
+
+		r.payments[i] = p
+		return p, nil
+	}
+
+	return models.Payment{}, models.ErrPaymentNotFound
+}
+
+func holdsClaim(status models.PaymentStatus) bool {
+	return status == models.PaymentStatusPending || status == models.PaymentStatusSucceeded
+}
+
+func matchesPayment(p models.Payment, f models.PaymentFilter) bool {
+	switch {
+	case f.ID != nil && p.ID != *f.ID:
+		return false
+	case f.TicketID != nil && !slices.Contains(p.TicketIDs, *f.TicketID):
+		return false
+	case f.Status != nil && p.Status != *f.Status:
+		return false
+	case f.ClaimKey != nil && p.ClaimKey != *f.ClaimKey:
+		return false
+	case f.ClaimedAt != nil && !p.ClaimedAt.Equal(*f.ClaimedAt):
+		return false
+	}
+
+	return true
+}
+
+// recordingProvider approves every charge, handing back the same payment
+// for a repeated idempotency key the way a real gateway does.
+type recordingProvider struct {
+	mu      sync.Mutex
+	charges map[string]string
+	calls   []string
+}
+
+func (p *recordingProvider) ProcessPayment(ctx context.Context, req payment.PaymentRequest) (payment.PaymentResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls = append(p.calls, req.IdempotencyKey)
+
+	if p.charges == nil {
+		p.charges = make(map[string]string)
+	}
+	id, ok := p.charges[req.IdempotencyKey]
+	if !ok {
+		id = fmt.Sprintf("pay-%d", len(p.charges)+1)
+		p.charges[req.IdempotencyKey] = id
+	}
+
+	return payment.PaymentResponse{PaymentID: id}, nil
+}
+
+func (p *recordingProvider) Refund(ctx context.Context, req payment.RefundRequest) (payment.RefundResponse, error) {
+	return payment.RefundResponse{RefundID: "refund-" + req.IdempotencyKey}, nil
+}
+
+// memTransferRepo is an in-memory TicketTransferRepository.
+type memTransferRepo struct {
+	mu        sync.Mutex
+	transfers []models.TicketTransfer
+}
+
+func (r *memTransferRepo) InsertOne(ctx context.Context, transfer models.TicketTransfer) (models.TicketTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transfer.ID = fmt.Sprintf("transfer-%d", len(r.transfers)+1)
+	r.transfers = append(r.transfers, transfer)
+
+	return transfer, nil
+}
+
+func (r *memTransferRepo) FindByID(ctx context.Context, id string) (models.TicketTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, transfer := range r.transfers {
+		if transfer.ID == id {
+			return transfer, nil
+		}
+	}
+
+	return models.TicketTransfer{}, models.ErrTransferNotFound
+}
+
+func (r *memTransferRepo) Resolve(ctx context.Context, id string, status models.TransferStatus) (models.TicketTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, transfer := range r.transfers {
+		if transfer.ID != id {
+			continue
+		}
+		if transfer.Status != models.TransferStatusPending {
+			return models.TicketTransfer{}, models.ErrTransferNotPending
+		}
+
+		r.transfers[i].Status = status
+		return r.transfers[i], nil
+	}
+
+	return models.TicketTransfer{}, models.ErrTransferNotFound
+}
+
+func (r *memTransferRepo) CancelPending(ctx context.Context, ticketID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, transfer := range r.transfers {
+		if transfer.TicketID == ticketID && transfer.Status == models.TransferStatusPending {
+			r.transfers[i].Status = models.TransferStatusCancelled
+		}
+	}
+
+	return nil
+}
+
+type nopEventRepo struct{}
+
+func (nopEventRepo) InsertMany(ctx context.Context, events []models.TicketEvent) error {
+	return nil
+}
+
+func (nopEventRepo) FindByTicket(ctx context.Context, ticketID string) ([]models.TicketEvent, error) {
+	return nil, nil
+}
+
+type fixedHalls struct {
+	hall models.Hall
+}
+
+func (h fixedHalls) FindBySession(ctx context.Context, sessionID string) (models.Hall, error) {
+	return h.hall, nil
+}
+
+type noSessions struct{}
+
+func (noSessions) FindByID(ctx context.Context, sessionID string) (models.Session, error) {
+	return models.Session{}, models.ErrSessionNotFound
+}
+
+// scheduledSessions is a schedule of the sessions it holds.
+type scheduledSessions map[string]models.Session
+
+func (s scheduledSessions) FindByID(ctx context.Context, sessionID string) (models.Session, error) {
+	session, ok := s[sessionID]
+	if !ok {
+		return models.Session{}, models.ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+type flatPrice struct {
+	price models.Money
+}
+
+func (p flatPrice) Price(ctx context.Context, req pricing.PriceRequest) (models.Money, error) {
+	return p.price, nil
+}
+
+// grantingLocker hands out every lock, leaving races to the store.
+type grantingLocker struct{}
+
+func (grantingLocker) AcquireSeatLock(ctx context.Context, sessionID, seatNumber string) (string, error) {
+	return "token", nil
+}
+
+func (grantingLocker) ReleaseSeatLock(ctx context.Context, sessionID, seatNumber, token string) error {
+	return nil
+}
+
+// nopCache caches nothing, so every read goes to the repository.
+type nopCache struct{}
+
+func (nopCache) CacheTicket(ctx context.Context, ticket *models.Ticket) error { return nil }
+func (nopCache) GetTicket(ctx context.Context, ticketID string) (*models.Ticket, error) {
+	return nil, nil
+}
+func (nopCache) InvalidateTicket(ctx context.Context, ticketID string) error { return nil }
+func (nopCache) CacheUserTickets(ctx context.Context, userID string, page *models.TicketPage) error {
+	return nil
+}
+func (nopCache) GetUserTickets(ctx context.Context, userID string) (*models.TicketPage, error) {
+	return nil, nil
+}
+func (nopCache) InvalidateUserTickets(ctx context.Context, userID string) error { return nil }
+func (nopCache) CacheSessionTickets(ctx context.Context, sessionID string, tickets []*models.Ticket) error {
+	return nil
+}
+func (nopCache) GetSessionTickets(ctx context.Context, sessionID string) ([]*models.Ticket, error) {
+	return nil, nil
+}
+func (nopCache) InvalidateSessionTickets(ctx context.Context, sessionID string) error { return nil }
+func (nopCache) CacheSeatMap(ctx context.Context, seatMap *models.SeatMap) error      { return nil }
+func (nopCache) GetSeatMap(ctx context.Context, sessionID string) (*models.SeatMap, error) {
+	return nil, nil
+}
+func (nopCache) CacheSeatState(ctx context.Context, sessionID, seatNumber string, state models.SeatState) error {
+	return nil
+}
+func (nopCache) GetSeatState(ctx context.Context, sessionID, seatNumber string) (*models.SeatState, error) {
+	return nil, nil
+}
+func (nopCache) InvalidateSeatMap(ctx context.Context, sessionID string) error          { return nil }
+func (nopCache) CacheSalesReport(ctx context.Context, report *models.SalesReport) error { return nil }
+func (nopCache) GetSalesReport(ctx context.Context, query models.SalesReportQuery) (*models.SalesReport, error) {
+	return nil, nil
+}
+func (nopCache) Ping(ctx context.Context) error { return nil }
+func (nopCache) Close() error                   { return nil }
+
+// newTestUseCase wires a use case over repo with a one-row hall A1…A10,
+// a flat price and nothing cached.
+func newTestUseCase(repo *memTicketRepo, locker cache.SeatLocker) *ticketUseCase {
+	hall := models.Hall{
+		ID:   "hall",
+		Rows: []models.HallRow{models.NewHallRow("A", 10)},
+	}
+
+	return NewTicketUseCase(
+		repo,
+		nopEventRepo{},
+		nil,
+		nil,
+		nil,
+		nil,
+		fixedHalls{hall: hall},
+		noSessions{},
+		nopCache{},
+		locker,
+		nil,
+		flatPrice{price: models.NewMoney(2500, "KZT")},
+		15*time.Minute,
+		time.Minute,
+		0,
+		false,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	).(*ticketUseCase)
+}
@@ -3,23 +3,85 @@ package usecase
 import (
 	"ap2final_ticket_service/internal/models"
 	"context"
+	"time"
 )
 
 type TicketUseCase interface {
-	ReserveTicket(ctx context.Context, sessionID, movieID, userID, seatNumber string, price float64) (*models.Ticket, error)
+	ReserveTicket(ctx context.Context, sessionID, movieID, userID, seatNumber string, price *models.Money, promoCode string) (*models.Ticket, error)
+	ReserveTickets(ctx context.Context, sessionID, movieID, userID string, seats []string, price *models.Money, promoCode string) ([]*models.Ticket, error)
 	ConfirmPayment(ctx context.Context, ticketID, paymentMethod string) (*models.Ticket, error)
 	CancelTicket(ctx context.Context, ticketID string) error
+	UpdateTicket(ctx context.Context, ticketID string, update models.TicketUpdateData) (*models.Ticket, error)
+	ExchangeTicket(ctx context.Context, ticketID, newSessionID, newSeatNumber string) (*models.Ticket, error)
+	InitiateTransfer(ctx context.Context, ticketID, toUserID string) (*models.TicketTransfer, error)
+	AcceptTransfer(ctx context.Context, transferID string) (*models.Ticket, error)
 	GetTicket(ctx context.Context, id string) (*models.Ticket, error)
-	GetUserTickets(ctx context.Context, userID string) ([]*models.Ticket, error)
-	GetAllTickets(ctx context.Context) ([]*models.Ticket, error)
-	GetMovieTickets(ctx context.Context, movieID string) ([]*models.Ticket, error)
+	GetUserTickets(ctx context.Context, userID string, page models.PageRequest) (*models.TicketPage, error)
+	GetAllTickets(ctx context.Context, page models.PageRequest) (*models.TicketPage, error)
+	GetMovieTickets(ctx context.Context, movieID string, page models.PageRequest) (*models.TicketPage, error)
+	GetSessionTickets(ctx context.Context, sessionID string, statuses []models.TicketStatus) ([]*models.Ticket, error)
+	SearchTickets(ctx context.Context, filter models.TicketFilter, page models.PageRequest) (*models.TicketPage, error)
+	StreamTickets(ctx context.Context, filter models.TicketFilter, page models.PageRequest, send func(*models.Ticket) error) error
 	CheckSeatAvailability(ctx context.Context, sessionID, seatNumber string) (bool, error)
+	GetSessionSeatMap(ctx context.Context, sessionID string) (*models.SeatMap, error)
+	QuotePrice(ctx context.Context, sessionID, movieID, seatNumber, promoCode string) (models.Money, error)
+	GetTicketHistory(ctx context.Context, ticketID string) ([]*models.TicketEvent, error)
+	GetPayment(ctx context.Context, id string) (*models.Payment, error)
+	GetTicketPayments(ctx context.Context, ticketID string) ([]*models.Payment, error)
+	CreatePromoCode(ctx context.Context, promo models.PromoCode) (*models.PromoCode, error)
+	GetSalesReport(ctx context.Context, from, to time.Time, groupBy models.ReportGroupBy) (*models.SalesReport, error)
+	GetOccupancyReport(ctx context.Context, sessionID string, interval models.OccupancyInterval) (*models.OccupancyReport, error)
+	ExpireReservations(ctx context.Context) (int, error)
+	RetryPendingRefunds(ctx context.Context) (int, error)
 }
 
 type TicketRepository interface {
 	InsertOne(ctx context.Context, ticket *models.Ticket) (models.Ticket, error)
+	InsertMany(ctx context.Context, tickets []models.Ticket) ([]models.Ticket, error)
 	FindOne(ctx context.Context, filter models.TicketFilter) (models.Ticket, error)
-	Find(ctx context.Context, filter models.TicketFilter) ([]models.Ticket, error)
+	Find(ctx context.Context, filter models.TicketFilter, page models.PageRequest) ([]models.Ticket, string, error)
+	Stream(ctx context.Context, filter models.TicketFilter, page models.PageRequest, fn func(models.Ticket) error) error
 	UpdateOne(ctx context.Context, filter models.TicketFilter, update models.TicketUpdateData) (models.Ticket, error)
 	IsSeatAvailable(ctx context.Context, sessionID, seatNumber string) (bool, error)
+	FindSeatStates(ctx context.Context, sessionID string) (map[string]models.SeatState, error)
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type TicketEventRepository interface {
+	InsertMany(ctx context.Context, events []models.TicketEvent) error
+	FindByTicket(ctx context.Context, ticketID string) ([]models.TicketEvent, error)
+}
+
+type PaymentRepository interface {
+	InsertOne(ctx context.Context, payment models.Payment) (models.Payment, error)
+	FindOne(ctx context.Context, filter models.PaymentFilter) (models.Payment, error)
+	Find(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error)
+	UpdateOne(ctx context.Context, filter models.PaymentFilter, update models.PaymentUpdateData) (models.Payment, error)
+}
+
+type PromoCodeRepository interface {
+	InsertOne(ctx context.Context, promo models.PromoCode) (models.PromoCode, error)
+	FindByCode(ctx context.Context, code string) (models.PromoCode, error)
+	Redeem(ctx context.Context, promo models.PromoCode, userID string, ticketIDs []string) error
+	Release(ctx context.Context, code, ticketID string) error
+}
+
+type TicketTransferRepository interface {
+	InsertOne(ctx context.Context, transfer models.TicketTransfer) (models.TicketTransfer, error)
+	FindByID(ctx context.Context, id string) (models.TicketTransfer, error)
+	Resolve(ctx context.Context, id string, status models.TransferStatus) (models.TicketTransfer, error)
+	CancelPending(ctx context.Context, ticketID string) error
+}
+
+type AnalyticsRepository interface {
+	SalesReport(ctx context.Context, query models.SalesReportQuery) ([]models.SalesReportRow, error)
+	SessionOccupancy(ctx context.Context, sessionID string, interval models.OccupancyInterval) ([]models.OccupancyDelta, error)
+}
+
+type HallRepository interface {
+	FindBySession(ctx context.Context, sessionID string) (models.Hall, error)
+}
+
+type SessionRepository interface {
+	FindByID(ctx context.Context, sessionID string) (models.Session, error)
 }
@@ -0,0 +1,360 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+	"ap2final_ticket_service/internal/payment"
+)
+
+func (uc *ticketUseCase) GetPayment(ctx context.Context, id string) (*models.Payment, error) {
+	record, err := uc.payments.FindOne(ctx, models.PaymentFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (uc *ticketUseCase) GetTicketPayments(ctx context.Context, ticketID string) ([]*models.Payment, error) {
+	if _, err := uc.GetTicket(ctx, ticketID); err != nil {
+		return nil, err
+	}
+
+	recordsFromDB, err := uc.payments.Find(ctx, models.PaymentFilter{TicketID: &ticketID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Payment, len(recordsFromDB))
+	for i := range recordsFromDB {
+		result[i] = &recordsFromDB[i]
+	}
+
+	return result, nil
+}
+
+// charge records a pending payment of amount for ticket, charges it
+// through the provider and stores the outcome. Declined charges stay on
+// record as FAILED.
+//
+// The record claims op on the ticket's current version before any money
+// moves, so a concurrent charge for the same version fails with
+// models.ErrPaymentInProgress instead of charging twice. A claim left
+// pending for longer than a provider call can take, by a process that died
+// mid-charge or failed to record the outcome, is taken over instead.
+func (uc *ticketUseCase) charge(ctx context.Context, op string, ticket models.Ticket, amount models.Money, method string) (models.Payment, error) {
+	now := time.Now()
+	claimKey := fmt.Sprintf("%s:%s:%d", op, ticket.ID, ticket.Version)
+
+	record, err := uc.payments.InsertOne(ctx, models.Payment{
+		TicketIDs: []string{ticket.ID},
+		Amount:    amount,
+		Method:    method,
+		Status:    models.PaymentStatusPending,
+		ClaimKey:  claimKey,
+		ClaimedAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if errors.Is(err, models.ErrPaymentInProgress) {
+		record, err = uc.takeOverClaim(ctx, claimKey, now)
+	}
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	// A taken-over claim reuses its idempotency key, so the provider returns
+	// the original charge if it went through rather than charging again.
+	res, err := uc.provider.ProcessPayment(ctx, payment.PaymentRequest{
+		Amount:         record.Amount,
+		Method:         record.Method,
+		Reference:      record.ID,
+		IdempotencyKey: "charge:" + record.ID,
+	})
+	if err != nil {
+		uc.log.Warn("payment declined", "ticket_id", ticket.ID, "payment_id", record.ID, "error", err)
+
+		reason := err.Error()
+		if _, err := uc.payments.UpdateOne(ctx, models.PaymentFilter{ID: &record.ID}, models.PaymentUpdateData{
+			Status:        models.PaymentStatusFailed.Ptr(),
+			FailureReason: &reason,
+		}); err != nil {
+			uc.log.Error("failed to record declined payment", "payment_id", record.ID, "error", err)
+		}
+
+		return models.Payment{}, paymentError(err)
+	}
+
+	updated, err := uc.payments.UpdateOne(ctx, models.PaymentFilter{ID: &record.ID}, models.PaymentUpdateData{
+		Status:      models.PaymentStatusSucceeded.Ptr(),
+		ProviderRef: &res.PaymentID,
+	})
+	if err != nil {
+		// The customer has been charged, so carry on and keep the reference in the logs.
+		uc.log.Error("failed to record successful payment", "payment_id", record.ID, "provider_ref", res.PaymentID, "error", err)
+
+		record.Status = models.PaymentStatusSucceeded
+		record.ProviderRef = res.PaymentID
+
+		return record, nil
+	}
+
+	return updated, nil
+}
+
+// takeOverClaim hands the pending charge holding claimKey to the caller
+// once it has gone unanswered for longer than a provider call can take.
+// A live or already settled claim yields models.ErrPaymentInProgress, as
+// does losing the takeover to another caller.
+func (uc *ticketUseCase) takeOverClaim(ctx context.Context, claimKey string, now time.Time) (models.Payment, error) {
+	claim, err := uc.payments.FindOne(ctx, models.PaymentFilter{
+		ClaimKey: &claimKey,
+		Status:   models.PaymentStatusPending.Ptr(),
+	})
+	if errors.Is(err, models.ErrPaymentNotFound) {
+		return models.Payment{}, models.ErrPaymentInProgress
+	}
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if now.Sub(claim.ClaimedAt) < uc.chargeClaimTimeout {
+		return models.Payment{}, models.ErrPaymentInProgress
+	}
+
+	taken, err := uc.payments.UpdateOne(ctx, models.PaymentFilter{
+		ID:        &claim.ID,
+		Status:    models.PaymentStatusPending.Ptr(),
+		ClaimedAt: &claim.ClaimedAt,
+	}, models.PaymentUpdateData{ClaimedAt: &now})
+	if errors.Is(err, models.ErrPaymentNotFound) {
+		return models.Payment{}, models.ErrPaymentInProgress
+	}
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	uc.log.Warn("taking over stale payment claim", "payment_id", taken.ID, "claimed_at", claim.ClaimedAt)
+
+	return taken, nil
+}
+
+// voidCharge gives back a charge whose ticket could not be marked paid.
+func (uc *ticketUseCase) voidCharge(ctx context.Context, ticket models.Ticket, record models.Payment) {
+	res, err := uc.provider.Refund(ctx, payment.RefundRequest{
+		PaymentID:      record.ProviderRef,
+		Amount:         record.Amount,
+		Reference:      record.ID,
+		IdempotencyKey: "void:" + record.ID,
+	})
+	if err != nil {
+		uc.log.Error("ticket charged but not marked paid, refund failed", "ticket_id", ticket.ID, "payment_id", record.ID, "error", err)
+		return
+	}
+
+	uc.recordRefund(ctx, record, record.Amount)
+
+	uc.log.Warn("ticket charged but not marked paid, charge refunded", "ticket_id", ticket.ID, "payment_id", record.ID, "refund_id", res.RefundID)
+}
+
+// refund returns what the customer paid for ticket, across every charge
+// made for it, and builds the update that moves the ticket to REFUNDED.
+func (uc *ticketUseCase) refund(ctx context.Context, ticket models.Ticket) (models.TicketUpdateData, error) {
+	if ticket.PaymentID == nil {
+		return models.TicketUpdateData{}, fmt.Errorf("%w: ticket %s has no payment reference", models.ErrRefundFailed, ticket.ID)
+	}
+
+	refundIDs, refunded, err := uc.refundUpTo(ctx, ticket, ticket.Price, fmt.Sprintf("refund:%s:%d", ticket.ID, ticket.Version))
+	if err != nil {
+		return models.TicketUpdateData{}, err
+	}
+
+	// Tickets paid in several charges, e.g. after an exchange, get one refund per charge.
+	refundID := strings.Join(refundIDs, ",")
+	now := time.Now()
+
+	return models.TicketUpdateData{
+		Status:       models.TicketStatusRefunded.Ptr(),
+		RefundID:     &refundID,
+		RefundAmount: &refunded,
+		RefundedAt:   &now,
+	}, nil
+}
+
+// refundUpTo gives back up to amount of what was charged for ticket,
+// newest charge first. Amounts already refunded are skipped, so retrying
+// after a partial failure does not refund anything twice. Each refund is
+// keyed on key and the charge it comes from; callers derive key from the
+// ticket version they read, so concurrent callers that read the same
+// ticket issue one refund at the provider between them.
+func (uc *ticketUseCase) refundUpTo(ctx context.Context, ticket models.Ticket, amount models.Money, key string) ([]string, models.Money, error) {
+	records, err := uc.payments.Find(ctx, models.PaymentFilter{TicketID: &ticket.ID})
+	if err != nil {
+		return nil, models.Money{}, err
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+
+	refunded := models.Money{Currency: amount.Currency}
+	remaining := amount.Amount
+
+	var refundIDs []string
+	for _, record := range records {
+		if remaining <= 0 {
+			break
+		}
+
+		if record.Status != models.PaymentStatusSucceeded && record.Status != models.PaymentStatusPartiallyRefunded {
+			continue
+		}
+
+		refundable := record.Amount.Amount - record.RefundedAmount.Amount
+		if refundable <= 0 {
+			continue
+		}
+
+		part := models.Money{Amount: min(refundable, remaining), Currency: record.Amount.Currency}
+
+		res, err := uc.provider.Refund(ctx, payment.RefundRequest{
+			PaymentID:      record.ProviderRef,
+			Amount:         part,
+			Reference:      ticket.ID,
+			IdempotencyKey: key + ":" + record.ID,
+		})
+		if err != nil {
+			uc.log.Warn("refund declined", "ticket_id", ticket.ID, "payment_id", record.ID, "error", err)
+
+			if errors.Is(err, models.ErrRefundFailed) {
+				return refundIDs, refunded, err
+			}
+
+			return refundIDs, refunded, fmt.Errorf("%w: %v", models.ErrRefundFailed, err)
+		}
+
+		uc.recordRefund(ctx, record, part)
+
+		refundIDs = append(refundIDs, res.RefundID)
+		refunded.Amount += part.Amount
+		remaining -= part.Amount
+	}
+
+	return refundIDs, refunded, nil
+}
+
+// settleRefund pays out ticket's pending refund and records what is still
+// owed. When the provider fails the remainder stays pending for
+// RetryPendingRefunds and the provider error is returned with the ticket.
+func (uc *ticketUseCase) settleRefund(ctx context.Context, ticket models.Ticket) (models.Ticket, error) {
+	pending := *ticket.PendingRefund
+
+	_, refunded, refundErr := uc.refundUpTo(ctx, ticket, pending.Amount, pending.Key)
+	if refundErr != nil && refunded.Amount == 0 {
+		return ticket, refundErr
+	}
+
+	// A zero amount clears the pending refund.
+	left := models.PendingRefund{Key: pending.Key}
+	if refundErr != nil {
+		left.Amount = models.Money{Amount: pending.Amount.Amount - refunded.Amount, Currency: pending.Amount.Currency}
+	} else if refunded.Amount < pending.Amount.Amount {
+		uc.log.Error("pending refund exceeds what is left of the charges", "ticket_id", ticket.ID, "owed", pending.Amount.String(), "refunded", refunded.String())
+	}
+
+	update := models.TicketUpdateData{PendingRefund: &left}
+	reason := fmt.Sprintf("refunded %s of %s owed", refunded, pending.Amount)
+
+	for attempt := 1; ; attempt++ {
+		updated, err := uc.applyTransition(ctx, ticket, update, reason)
+		if err == nil {
+			return updated, refundErr
+		}
+
+		if !errors.Is(err, models.ErrTicketVersionConflict) || attempt == maxTransitionAttempts {
+			uc.log.Error("pending refund paid but not recorded", "ticket_id", ticket.ID, "refunded", refunded.String(), "error", err)
+			return ticket, err
+		}
+
+		ticket, err = uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticket.ID})
+		if err != nil {
+			return models.Ticket{}, err
+		}
+
+		// Another settlement of the same refund got there first.
+		if ticket.PendingRefund == nil || ticket.PendingRefund.Key != pending.Key {
+			return ticket, refundErr
+		}
+	}
+}
+
+// RetryPendingRefunds pays out refunds that failed earlier, such as the
+// price difference of an exchange, and returns how many were settled.
+func (uc *ticketUseCase) RetryPendingRefunds(ctx context.Context) (int, error) {
+	owed := true
+
+	settled := 0
+	err := uc.eachTicket(ctx, models.TicketFilter{PendingRefund: &owed}, func(ticket models.Ticket) error {
+		updated, err := uc.settleRefund(ctx, ticket)
+		if err != nil {
+			uc.log.Warn("pending refund still not paid", "ticket_id", ticket.ID, "error", err)
+			return nil
+		}
+
+		settled++
+
+		_ = uc.cache.InvalidateTicket(ctx, updated.ID)
+
+		_ = uc.cache.InvalidateUserTickets(ctx, updated.UserID)
+
+		_ = uc.cache.InvalidateSessionTickets(ctx, updated.SessionID)
+
+		return nil
+	})
+	if err != nil {
+		return settled, err
+	}
+
+	return settled, nil
+}
+
+// recordRefund adds amount to what has been refunded on record.
+func (uc *ticketUseCase) recordRefund(ctx context.Context, record models.Payment, amount models.Money) {
+	refunded, err := record.RefundedAmount.Add(amount)
+	if err != nil {
+		uc.log.Error("failed to record refund", "payment_id", record.ID, "error", err)
+		return
+	}
+
+	status := models.PaymentStatusPartiallyRefunded
+	if refunded.Amount >= record.Amount.Amount {
+		status = models.PaymentStatusRefunded
+	}
+
+	_, err = uc.payments.UpdateOne(ctx, models.PaymentFilter{ID: &record.ID}, models.PaymentUpdateData{
+		Status:         &status,
+		RefundedAmount: &refunded,
+	})
+	if err != nil {
+		uc.log.Error("failed to record refund", "payment_id", record.ID, "error", err)
+	}
+}
+
+// paymentError keeps the provider's decline reason when it is one we
+// expose and folds everything else into ErrPaymentFailed.
+func paymentError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrInsufficientFunds),
+		errors.Is(err, models.ErrInvalidPaymentMethod),
+		errors.Is(err, models.ErrPaymentFailed):
+		return err
+	default:
+		return fmt.Errorf("%w: %v", models.ErrPaymentFailed, err)
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"ap2final_ticket_service/internal/adapter/cache"
+	"ap2final_ticket_service/internal/models"
+)
+
+func TestConfirmPaymentPendingClaim(t *testing.T) {
+	const claimTimeout = time.Minute
+
+	tests := []struct {
+		name      string
+		claimAge  time.Duration
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "live claim", claimAge: claimTimeout / 2, wantErr: models.ErrPaymentInProgress},
+		{name: "stale claim", claimAge: 2 * claimTimeout, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTicketRepo{}
+			payments := &memPaymentRepo{}
+			provider := &recordingProvider{}
+
+			uc := newTestUseCase(repo, cache.NewMemorySeatLocker(time.Minute))
+			uc.payments = payments
+			uc.provider = provider
+			uc.chargeClaimTimeout = claimTimeout
+
+			ticket, err := repo.InsertOne(context.Background(), &models.Ticket{
+				SessionID:     "665f1c2e8b3e4a0012345678",
+				SeatNumber:    "A1",
+				Price:         models.NewMoney(2500, "KZT"),
+				PaymentMethod: "card",
+				Status:        models.TicketStatusReserved,
+				ExpiresAt:     time.Now().Add(time.Minute),
+			})
+			if err != nil {
+				t.Fatalf("InsertOne() error = %v", err)
+			}
+
+			// A confirmation that claimed this version and never reported back.
+			claimedAt := time.Now().Add(-tt.claimAge)
+			claim, err := payments.InsertOne(context.Background(), models.Payment{
+				TicketIDs: []string{ticket.ID},
+				Amount:    ticket.Price,
+				Method:    "card",
+				Status:    models.PaymentStatusPending,
+				ClaimKey:  fmt.Sprintf("%s:%s:%d", chargeConfirm, ticket.ID, ticket.Version),
+				ClaimedAt: claimedAt,
+				CreatedAt: claimedAt,
+			})
+			if err != nil {
+				t.Fatalf("InsertOne() claim error = %v", err)
+			}
+
+			paid, err := uc.ConfirmPayment(context.Background(), ticket.ID, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmPayment() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(provider.calls) != tt.wantCalls {
+				t.Fatalf("provider saw %d charges, want %d", len(provider.calls), tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// The takeover reuses the claim's key, so a charge that did go
+			// through before the crash is not taken twice.
+			if want := "charge:" + claim.ID; provider.calls[0] != want {
+				t.Errorf("provider idempotency key = %q, want %q", provider.calls[0], want)
+			}
+
+			if paid.Status != models.TicketStatusPaid {
+				t.Errorf("ticket status = %s, want %s", paid.Status, models.TicketStatusPaid)
+			}
+			if paid.PaymentID == nil || *paid.PaymentID != claim.ID {
+				t.Errorf("ticket payment = %v, want %s", paid.PaymentID, claim.ID)
+			}
+
+			record, err := payments.FindOne(context.Background(), models.PaymentFilter{ID: &claim.ID})
+			if err != nil {
+				t.Fatalf("FindOne() error = %v", err)
+			}
+			if record.Status != models.PaymentStatusSucceeded {
+				t.Errorf("payment status = %s, want %s", record.Status, models.PaymentStatusSucceeded)
+			}
+		})
+	}
+}
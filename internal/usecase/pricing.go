@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ap2final_ticket_service/internal/models"
+	"ap2final_ticket_service/internal/pricing"
+)
+
+func (uc *ticketUseCase) QuotePrice(ctx context.Context, sessionID, movieID, seatNumber, promoCode string) (models.Money, error) {
+	hall, err := uc.sessionHall(ctx, sessionID)
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	if hall != nil && !hall.HasSeat(seatNumber) {
+		return models.Money{}, models.ErrInvalidSeatNumber
+	}
+
+	session, err := uc.sessionSchedule(ctx, sessionID)
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	promo, err := uc.promoCode(ctx, promoCode, movieID, sessionID)
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	price, _, err := uc.priceSeat(ctx, sessionID, seatNumber, session, hall, promo)
+
+	return price, err
+}
+
+// sessionSchedule returns the scheduled session, or nil when the schedule
+// does not know it. Pricing then falls back to rules that don't need it.
+func (uc *ticketUseCase) sessionSchedule(ctx context.Context, sessionID string) (*models.Session, error) {
+	session, err := uc.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			uc.log.Debug("session not in schedule", "session_id", sessionID)
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// priceSeat returns what the seat costs after promo, if any, and the
+// discount the promo took off.
+func (uc *ticketUseCase) priceSeat(
+	ctx context.Context,
+	sessionID, seatNumber string,
+	session *models.Session,
+	hall *models.Hall,
+	promo *models.PromoCode,
+) (models.Money, *models.Money, error) {
+	price, err := uc.pricing.Price(ctx, pricing.PriceRequest{
+		SessionID:  sessionID,
+		SeatNumber: seatNumber,
+		Session:    session,
+		Hall:       hall,
+	})
+	if err != nil {
+		return models.Money{}, nil, err
+	}
+
+	if promo == nil {
+		return price, nil, nil
+	}
+
+	discounted, discount, err := promo.Apply(price)
+	if err != nil {
+		return models.Money{}, nil, err
+	}
+
+	return discounted, &discount, nil
+}
+
+// checkPrice rejects a client-supplied price that differs from the quote.
+// A nil price means the client accepts whatever the seat costs.
+func checkPrice(quoted models.Money, supplied *models.Money) error {
+	if supplied == nil || *supplied == quoted {
+		return nil
+	}
+
+	return fmt.Errorf("%w: seat costs %s, got %s", models.ErrPriceMismatch, quoted, *supplied)
+}
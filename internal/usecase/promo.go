@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+)
+
+func (uc *ticketUseCase) CreatePromoCode(ctx context.Context, promo models.PromoCode) (*models.PromoCode, error) {
+	promo.Code = models.NormalizePromoCode(promo.Code)
+	promo.Redemptions = 0
+	promo.CreatedAt = time.Now()
+
+	if err := promo.Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.promos.InsertOne(ctx, promo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// promoCodeOf returns the code to store on tickets reserved with promo.
+func promoCodeOf(promo *models.PromoCode) *string {
+	if promo == nil {
+		return nil
+	}
+
+	return &promo.Code
+}
+
+// releasesPromo reports whether a ticket moving to status no longer uses
+// the promo code it was reserved with.
+func releasesPromo(status models.TicketStatus) bool {
+	switch status {
+	case models.TicketStatusCancelled, models.TicketStatusExpired, models.TicketStatusRefunded:
+		return true
+	default:
+		return false
+	}
+}
+
+// promoCode looks up code and checks it can be used for the session. An
+// empty code means no promo and returns nil.
+func (uc *ticketUseCase) promoCode(ctx context.Context, code, movieID, sessionID string) (*models.PromoCode, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	promo, err := uc.promos.FindByCode(ctx, models.NormalizePromoCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := promo.CheckApplicable(time.Now(), movieID, sessionID); err != nil {
+		return nil, err
+	}
+
+	return &promo, nil
+}
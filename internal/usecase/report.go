@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+)
+
+// GetSalesReport aggregates reservations, sales and refunds made in
+// [from, to), each by when it happened. Results are cached briefly, since management dashboards
+// tend to ask for the same period repeatedly.
+func (uc *ticketUseCase) GetSalesReport(
+	ctx context.Context,
+	from, to time.Time,
+	groupBy models.ReportGroupBy,
+) (*models.SalesReport, error) {
+	query := models.SalesReportQuery{From: from, To: to, GroupBy: groupBy}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	cached, err := uc.cache.GetSalesReport(ctx, query)
+	if err != nil {
+		uc.log.Warn("failed to get sales report from cache", "group_by", groupBy, "error", err)
+	}
+
+	if cached != nil {
+		return cached, nil
+	}
+
+	rows, err := uc.analytics.SalesReport(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SalesReport{
+		SalesReportQuery: query,
+		Rows:             rows,
+		GeneratedAt:      time.Now(),
+	}
+
+	if err := uc.cache.CacheSalesReport(ctx, report); err != nil {
+		uc.log.Warn("failed to cache sales report", "group_by", groupBy, "error", err)
+	}
+
+	return report, nil
+}
+
+// GetOccupancyReport shows how a session filled up over time. Capacity
+// comes from the hall layout linked to the session, or the configured
+// report capacity when there is none.
+func (uc *ticketUseCase) GetOccupancyReport(
+	ctx context.Context,
+	sessionID string,
+	interval models.OccupancyInterval,
+) (*models.OccupancyReport, error) {
+	if interval == "" {
+		interval = models.OccupancyIntervalDay
+	}
+
+	if !interval.IsValid() {
+		return nil, fmt.Errorf("%w: unknown interval %q", models.ErrInvalidReportRequest, interval)
+	}
+
+	capacity := uc.reportCapacity
+
+	hall, err := uc.halls.FindBySession(ctx, sessionID)
+	switch {
+	case err == nil:
+		capacity = hall.Capacity()
+	case !errors.Is(err, models.ErrHallNotFound):
+		return nil, err
+	}
+
+	if capacity <= 0 {
+		return nil, models.ErrHallNotFound
+	}
+
+	deltas, err := uc.analytics.SessionOccupancy(ctx, sessionID, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	report := models.NewOccupancyReport(sessionID, capacity, interval, deltas)
+	report.GeneratedAt = time.Now()
+
+	return &report, nil
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"ap2final_ticket_service/internal/adapter/cache"
+	"ap2final_ticket_service/internal/models"
+)
+
+func TestSearchTicketsFilters(t *testing.T) {
+	sessionID := "665f1c2e8b3e4a0012345678"
+	movieID := "665f1c2e8b3e4a0012345679"
+	userID := "665f1c2e8b3e4a00000000a1"
+	prefix := "B"
+	method := "card"
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	justBefore := from.Add(-time.Nanosecond)
+	low := models.NewMoney(1000, "KZT")
+	high := models.NewMoney(5000, "KZT")
+	negative := models.NewMoney(-1, "KZT")
+	dollars := models.NewMoney(5000, "USD")
+
+	tests := []struct {
+		name    string
+		filter  models.TicketFilter
+		wantErr error
+	}{
+		{name: "empty", filter: models.TicketFilter{}},
+		{
+			name: "session seat prefix and statuses",
+			filter: models.TicketFilter{
+				SessionID:  &sessionID,
+				SeatPrefix: &prefix,
+				Statuses:   []models.TicketStatus{models.TicketStatusReserved, models.TicketStatusPaid},
+			},
+		},
+		{
+			name: "user movie and payment method",
+			filter: models.TicketFilter{
+				UserID:        &userID,
+				MovieID:       &movieID,
+				PaymentMethod: &method,
+			},
+		},
+		{
+			name: "closed created and purchase ranges",
+			filter: models.TicketFilter{
+				CreatedFrom:   &from,
+				CreatedTo:     &to,
+				PurchasedFrom: &from,
+				PurchasedTo:   &to,
+			},
+		},
+		{name: "open ended ranges", filter: models.TicketFilter{CreatedFrom: &from, PurchasedTo: &to}},
+		{name: "single instant", filter: models.TicketFilter{CreatedFrom: &from, CreatedTo: &from}},
+		{name: "price range", filter: models.TicketFilter{MinPrice: &low, MaxPrice: &high}},
+		{name: "min price only", filter: models.TicketFilter{MinPrice: &low}},
+		{
+			name: "everything",
+			filter: models.TicketFilter{
+				SessionID:     &sessionID,
+				MovieID:       &movieID,
+				UserID:        &userID,
+				SeatPrefix:    &prefix,
+				PaymentMethod: &method,
+				Statuses:      []models.TicketStatus{models.TicketStatusPaid},
+				CreatedFrom:   &from,
+				CreatedTo:     &to,
+				PurchasedFrom: &from,
+				PurchasedTo:   &to,
+				MinPrice:      &low,
+				MaxPrice:      &high,
+			},
+		},
+		{
+			name:    "unknown status",
+			filter:  models.TicketFilter{Statuses: []models.TicketStatus{models.TicketStatusPaid, "LOST"}},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "blank status",
+			filter:  models.TicketFilter{Statuses: []models.TicketStatus{""}},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "lower case status",
+			filter:  models.TicketFilter{Statuses: []models.TicketStatus{"paid"}},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "empty status set",
+			filter:  models.TicketFilter{Statuses: []models.TicketStatus{}},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "seat prefix without session",
+			filter:  models.TicketFilter{SeatPrefix: &prefix, MovieID: &movieID},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "created range reversed",
+			filter:  models.TicketFilter{CreatedFrom: &to, CreatedTo: &from},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "created range reversed by a nanosecond",
+			filter:  models.TicketFilter{CreatedFrom: &from, CreatedTo: &justBefore},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "purchase range reversed",
+			filter:  models.TicketFilter{PurchasedFrom: &to, PurchasedTo: &from},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "price range reversed",
+			filter:  models.TicketFilter{MinPrice: &high, MaxPrice: &low},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "negative price",
+			filter:  models.TicketFilter{MaxPrice: &negative},
+			wantErr: models.ErrInvalidTicketFilter,
+		},
+		{
+			name:    "price bounds in different currencies",
+			filter:  models.TicketFilter{MinPrice: &low, MaxPrice: &dollars},
+			wantErr: models.ErrCurrencyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTicketRepo{}
+			uc := newTestUseCase(repo, cache.NewMemorySeatLocker(time.Minute))
+
+			_, err := uc.SearchTickets(context.Background(), tt.filter, models.PageRequest{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchTickets() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if repo.lastFilter != nil {
+					t.Errorf("rejected filter reached the repository")
+				}
+				return
+			}
+
+			if repo.lastFilter == nil {
+				t.Fatalf("filter never reached the repository")
+			}
+			if !reflect.DeepEqual(*repo.lastFilter, tt.filter) {
+				t.Errorf("repository got filter %+v, want %+v", *repo.lastFilter, tt.filter)
+			}
+			if repo.lastPage.Size != models.DefaultPageSize {
+				t.Errorf("repository got page size %d, want the default %d", repo.lastPage.Size, models.DefaultPageSize)
+			}
+		})
+	}
+}
+
+// TestStreamTicketsRejectsBadFilters checks the stream refuses the filters
+// a search refuses before it reads anything.
+func TestStreamTicketsRejectsBadFilters(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	low := models.NewMoney(1000, "KZT")
+	high := models.NewMoney(5000, "KZT")
+
+	tests := []struct {
+		name   string
+		filter models.TicketFilter
+	}{
+		{name: "created range reversed", filter: models.TicketFilter{CreatedFrom: &to, CreatedTo: &from}},
+		{name: "purchase range reversed", filter: models.TicketFilter{PurchasedFrom: &to, PurchasedTo: &from}},
+		{name: "price range reversed", filter: models.TicketFilter{MinPrice: &high, MaxPrice: &low}},
+		{name: "empty status set", filter: models.TicketFilter{Statuses: []models.TicketStatus{}}},
+		{name: "unknown status", filter: models.TicketFilter{Statuses: []models.TicketStatus{"LOST"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTicketRepo{}
+			uc := newTestUseCase(repo, cache.NewMemorySeatLocker(time.Minute))
+
+			sent := 0
+			err := uc.StreamTickets(context.Background(), tt.filter, models.PageRequest{}, func(*models.Ticket) error {
+				sent++
+				return nil
+			})
+			if !errors.Is(err, models.ErrInvalidTicketFilter) {
+				t.Fatalf("StreamTickets() error = %v, want %v", err, models.ErrInvalidTicketFilter)
+			}
+
+			if repo.lastFilter != nil || sent != 0 {
+				t.Errorf("rejected filter reached the repository")
+			}
+		})
+	}
+}
@@ -1,35 +1,154 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"log/slog"
+	"slices"
 	"time"
 
 	"ap2final_ticket_service/internal/adapter/cache"
 	"ap2final_ticket_service/internal/models"
+	"ap2final_ticket_service/internal/payment"
+	"ap2final_ticket_service/internal/pricing"
+)
+
+// maxTransitionAttempts bounds retries of a status change that keeps losing
+// the version check to concurrent writers.
+const maxTransitionAttempts = 3
+
+// Operations a ticket can be charged for; each claims the ticket version once.
+const (
+	chargeConfirm  = "confirm"
+	chargeExchange = "exchange"
+)
+
+// seatLockReleaseTimeout bounds releasing a seat lock after the request
+// that took it has finished.
+const seatLockReleaseTimeout = 2 * time.Second
+
+// sweepPageSize bounds how many tickets a background sweep loads at once.
+const sweepPageSize = 200
+
+// Reasons recorded in the ticket history.
+const (
+	reasonReserved         = "seat reserved"
+	reasonPaymentConfirmed = "payment confirmed"
+	reasonCancelled        = "reservation cancelled"
+	reasonRefunded         = "paid ticket cancelled and refunded"
+	reasonExpired          = "reservation hold expired"
+	reasonUsed             = "ticket used"
 )
 
 type ticketUseCase struct {
-	repo  TicketRepository
-	cache cache.TicketCache
-	log   *slog.Logger
+	repo                  TicketRepository
+	events                TicketEventRepository
+	halls                 HallRepository
+	sessions              SessionRepository
+	cache                 cache.TicketCache
+	locker                cache.SeatLocker
+	payments              PaymentRepository
+	promos                PromoCodeRepository
+	transfers             TicketTransferRepository
+	analytics             AnalyticsRepository
+	provider              payment.Service
+	pricing               pricing.Engine
+	holdDuration          time.Duration
+	chargeClaimTimeout    time.Duration
+	reportCapacity        int
+	allowUnmappedSessions bool
+	log                   *slog.Logger
 }
 
-func NewTicketUseCase(repo TicketRepository, cache cache.TicketCache, log *slog.Logger) TicketUseCase {
+func NewTicketUseCase(
+	repo TicketRepository,
+	events TicketEventRepository,
+	payments PaymentRepository,
+	promos PromoCodeRepository,
+	transfers TicketTransferRepository,
+	analytics AnalyticsRepository,
+	halls HallRepository,
+	sessions SessionRepository,
+	cache cache.TicketCache,
+	locker cache.SeatLocker,
+	provider payment.Service,
+	pricing pricing.Engine,
+	holdDuration time.Duration,
+	chargeClaimTimeout time.Duration,
+	reportCapacity int,
+	allowUnmappedSessions bool,
+	log *slog.Logger,
+) TicketUseCase {
 	return &ticketUseCase{
-		repo:  repo,
-		cache: cache,
-		log:   log,
+		repo:                  repo,
+		events:                events,
+		halls:                 halls,
+		sessions:              sessions,
+		cache:                 cache,
+		locker:                locker,
+		payments:              payments,
+		promos:                promos,
+		transfers:             transfers,
+		analytics:             analytics,
+		provider:              provider,
+		pricing:               pricing,
+		holdDuration:          holdDuration,
+		chargeClaimTimeout:    chargeClaimTimeout,
+		reportCapacity:        reportCapacity,
+		allowUnmappedSessions: allowUnmappedSessions,
+		log:                   log,
 	}
 }
 
 func (uc *ticketUseCase) ReserveTicket(
 	ctx context.Context,
 	sessionID, movieID, userID, seatNumber string,
-	price float64,
+	price *models.Money,
+	promoCode string,
 ) (*models.Ticket, error) {
-	if available, err := uc.cache.GetSeatAvailability(ctx, sessionID, seatNumber); err == nil && available != nil {
-		if !*available {
+	hall, err := uc.sessionHall(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hall != nil {
+		if !hall.HasSeat(seatNumber) {
+			return nil, models.ErrInvalidSeatNumber
+		}
+
+		if err := uc.checkCapacity(ctx, sessionID, *hall, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	session, err := uc.sessionSchedule(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	promo, err := uc.promoCode(ctx, promoCode, movieID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	quoted, discount, err := uc.priceSeat(ctx, sessionID, seatNumber, session, hall, promo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPrice(quoted, price); err != nil {
+		return nil, err
+	}
+
+	token, err := uc.locker.AcquireSeatLock(ctx, sessionID, seatNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer uc.releaseSeatLock(ctx, sessionID, seatNumber, token)
+
+	if state, err := uc.cache.GetSeatState(ctx, sessionID, seatNumber); err == nil && state != nil {
+		if *state != models.SeatStateFree {
 			return nil, models.ErrSeatAlreadyTaken
 		}
 	} else {
@@ -38,26 +157,51 @@ func (uc *ticketUseCase) ReserveTicket(
 			return nil, err
 		}
 		if !available {
-			_ = uc.cache.CacheSeatAvailability(ctx, sessionID, seatNumber, false)
+			_ = uc.cache.InvalidateSeatMap(ctx, sessionID)
 			return nil, models.ErrSeatAlreadyTaken
 		}
-		_ = uc.cache.CacheSeatAvailability(ctx, sessionID, seatNumber, true)
 	}
 
+	now := time.Now()
+
 	ticket := &models.Ticket{
 		SessionID:    sessionID,
 		MovieID:      movieID,
 		UserID:       userID,
 		SeatNumber:   seatNumber,
-		Price:        price,
+		Price:        quoted,
+		Discount:     discount,
+		PromoCode:    promoCodeOf(promo),
 		Status:       models.TicketStatusReserved,
 		PurchaseTime: time.Time{},
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ExpiresAt:    now.Add(uc.holdDuration),
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
-	createdTicket, err := uc.repo.InsertOne(ctx, ticket)
+	var createdTicket models.Ticket
+	err = uc.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		createdTicket, err = uc.repo.InsertOne(ctx, ticket)
+		if err != nil {
+			return err
+		}
+
+		if promo != nil {
+			if err := uc.promos.Redeem(ctx, *promo, userID, []string{createdTicket.ID}); err != nil {
+				return err
+			}
+		}
+
+		return uc.events.InsertMany(ctx, []models.TicketEvent{
+			newTicketEvent(ctx, createdTicket, "", reasonReserved),
+		})
+	})
 	if err != nil {
+		if errors.Is(err, models.ErrSeatAlreadyTaken) {
+			_ = uc.cache.InvalidateSeatMap(ctx, sessionID)
+		}
+
 		return nil, err
 	}
 
@@ -65,49 +209,226 @@ func (uc *ticketUseCase) ReserveTicket(
 		uc.log.Warn("failed to cache ticket", "ticket_id", createdTicket.ID, "error", err)
 	}
 
-	_ = uc.cache.CacheSeatAvailability(ctx, sessionID, seatNumber, false)
+	_ = uc.cache.CacheSeatState(ctx, sessionID, seatNumber, models.SeatStateReserved)
 
 	_ = uc.cache.InvalidateUserTickets(ctx, userID)
 
+	_ = uc.cache.InvalidateSessionTickets(ctx, sessionID)
+
 	return &createdTicket, nil
 }
 
-func (uc *ticketUseCase) ConfirmPayment(
+func (uc *ticketUseCase) ReserveTickets(
 	ctx context.Context,
-	ticketID, paymentMethod string,
-) (*models.Ticket, error) {
-	existing, err := uc.cache.GetTicket(ctx, ticketID)
+	sessionID, movieID, userID string,
+	seats []string,
+	price *models.Money,
+	promoCode string,
+) ([]*models.Ticket, error) {
+	if len(seats) == 0 {
+		return nil, models.ErrInvalidTicketData
+	}
+
+	hall, err := uc.sessionHall(ctx, sessionID)
 	if err != nil {
-		uc.log.Warn("failed to get ticket from cache", "ticket_id", ticketID, "error", err)
+		return nil, err
 	}
 
-	if existing == nil {
-		existingFromDB, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
+	seatErrs := make(map[string]error)
+	seen := make(map[string]struct{}, len(seats))
+	for _, seat := range seats {
+		if _, ok := seen[seat]; ok || (hall != nil && !hall.HasSeat(seat)) {
+			seatErrs[seat] = models.ErrInvalidSeatNumber
+		}
+		seen[seat] = struct{}{}
+	}
+	if len(seatErrs) > 0 {
+		return nil, &models.SeatReservationError{Seats: seatErrs}
+	}
+
+	session, err := uc.sessionSchedule(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	promo, err := uc.promoCode(ctx, promoCode, movieID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]models.Money, len(seats))
+	discounts := make(map[string]*models.Money, len(seats))
+	for _, seat := range seats {
+		quoted, discount, err := uc.priceSeat(ctx, sessionID, seat, session, hall, promo)
 		if err != nil {
 			return nil, err
 		}
-		existing = &existingFromDB
+
+		if err := checkPrice(quoted, price); err != nil {
+			seatErrs[seat] = err
+		}
+		prices[seat] = quoted
+		discounts[seat] = discount
+	}
+	if len(seatErrs) > 0 {
+		return nil, &models.SeatReservationError{Seats: seatErrs}
+	}
+
+	if hall != nil {
+		if err := uc.checkCapacity(ctx, sessionID, *hall, len(seats)); err != nil {
+			return nil, err
+		}
 	}
 
-	if existing.Status != models.TicketStatusReserved {
-		return nil, models.ErrTicketNotReserved
+	tokens := make(map[string]string, len(seats))
+	defer func() {
+		for seat, token := range tokens {
+			uc.releaseSeatLock(ctx, sessionID, seat, token)
+		}
+	}()
+
+	for _, seat := range seats {
+		token, err := uc.locker.AcquireSeatLock(ctx, sessionID, seat)
+		if err != nil {
+			seatErrs[seat] = err
+			continue
+		}
+		tokens[seat] = token
+
+		available, err := uc.repo.IsSeatAvailable(ctx, sessionID, seat)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			seatErrs[seat] = models.ErrSeatAlreadyTaken
+		}
+	}
+	if len(seatErrs) > 0 {
+		_ = uc.cache.InvalidateSeatMap(ctx, sessionID)
+		return nil, &models.SeatReservationError{Seats: seatErrs}
 	}
 
-	paymentID := "pay_" + time.Now().Format("20060102150405")
+	now := time.Now()
+
+	tickets := make([]models.Ticket, len(seats))
+	for i, seat := range seats {
+		tickets[i] = models.Ticket{
+			SessionID:  sessionID,
+			MovieID:    movieID,
+			UserID:     userID,
+			SeatNumber: seat,
+			Price:      prices[seat],
+			Discount:   discounts[seat],
+			PromoCode:  promoCodeOf(promo),
+			Status:     models.TicketStatusReserved,
+			ExpiresAt:  now.Add(uc.holdDuration),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+
+	var created []models.Ticket
+	err = uc.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = uc.repo.InsertMany(ctx, tickets)
+		if err != nil {
+			return err
+		}
+
+		if promo != nil {
+			ids := make([]string, len(created))
+			for i := range created {
+				ids[i] = created[i].ID
+			}
+
+			if err := uc.promos.Redeem(ctx, *promo, userID, ids); err != nil {
+				return err
+			}
+		}
+
+		events := make([]models.TicketEvent, len(created))
+		for i := range created {
+			events[i] = newTicketEvent(ctx, created[i], "", reasonReserved)
+		}
+
+		return uc.events.InsertMany(ctx, events)
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrSeatAlreadyTaken) {
+			_ = uc.cache.InvalidateSeatMap(ctx, sessionID)
+		}
+
+		return nil, err
+	}
+
+	result := make([]*models.Ticket, len(created))
+	for i := range created {
+		result[i] = &created[i]
+
+		if err := uc.cache.CacheTicket(ctx, result[i]); err != nil {
+			uc.log.Warn("failed to cache ticket", "ticket_id", result[i].ID, "error", err)
+		}
+
+		_ = uc.cache.CacheSeatState(ctx, sessionID, result[i].SeatNumber, models.SeatStateReserved)
+	}
+
+	_ = uc.cache.InvalidateUserTickets(ctx, userID)
+
+	_ = uc.cache.InvalidateSessionTickets(ctx, sessionID)
+
+	return result, nil
+}
+
+func (uc *ticketUseCase) ConfirmPayment(
+	ctx context.Context,
+	ticketID, paymentMethod string,
+) (*models.Ticket, error) {
+	// Money moves below, so read the stored ticket rather than a cached copy.
+	existing, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.IsExpired(time.Now()) {
+		return nil, models.ErrTicketExpired
+	}
+
+	if err := existing.Status.TransitionTo(models.TicketStatusPaid); err != nil {
+		return nil, err
+	}
+
+	// Without an explicit method, pay with the one chosen at reservation.
+	if paymentMethod == "" {
+		paymentMethod = existing.PaymentMethod
+	}
+	if paymentMethod == "" {
+		return nil, models.ErrInvalidPaymentMethod
+	}
+
+	record, err := uc.charge(ctx, chargeConfirm, existing, existing.Price, paymentMethod)
+	if errors.Is(err, models.ErrPaymentInProgress) {
+		// A concurrent confirmation holds this version; report its outcome if it already landed.
+		if current, findErr := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID}); findErr == nil {
+			if err := current.Status.TransitionTo(models.TicketStatusPaid); err != nil {
+				return nil, err
+			}
+		}
+		return nil, models.ErrPaymentInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	update := models.TicketUpdateData{
 		Status:        models.TicketStatusPaid.Ptr(),
-		PaymentMethod: &paymentMethod,
-		PaymentID:     &paymentID,
+		PaymentMethod: &record.Method,
+		PaymentID:     &record.ID,
 		PurchaseTime:  models.TimePtr(time.Now()),
 	}
 
-	updatedTicket, err := uc.repo.UpdateOne(
-		ctx,
-		models.TicketFilter{ID: &ticketID},
-		update,
-	)
+	updatedTicket, err := uc.transition(ctx, existing, update, reasonPaymentConfirmed)
 	if err != nil {
+		uc.voidCharge(ctx, existing, record)
 		return nil, err
 	}
 
@@ -115,37 +436,55 @@ func (uc *ticketUseCase) ConfirmPayment(
 		uc.log.Warn("failed to cache paid ticket", "ticket_id", updatedTicket.ID, "error", err)
 	}
 
+	_ = uc.cache.CacheSeatState(ctx, updatedTicket.SessionID, updatedTicket.SeatNumber, models.SeatStatePaid)
+
 	_ = uc.cache.InvalidateUserTickets(ctx, updatedTicket.UserID)
 
+	_ = uc.cache.InvalidateSessionTickets(ctx, updatedTicket.SessionID)
+
 	return &updatedTicket, nil
 }
 
 func (uc *ticketUseCase) CancelTicket(ctx context.Context, ticketID string) error {
-	existing, err := uc.cache.GetTicket(ctx, ticketID)
+	// A refund may be issued below, so read the stored ticket rather than a cached copy.
+	existing, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
 	if err != nil {
-		uc.log.Warn("failed to get ticket from cache for cancellation", "ticket_id", ticketID, "error", err)
+		return err
+	}
+
+	// Cancelling a paid ticket means giving the money back.
+	next := models.TicketStatusCancelled
+	if existing.Status == models.TicketStatusPaid {
+		next = models.TicketStatusRefunded
 	}
 
-	if existing == nil {
-		existingFromDB, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
+	if err := existing.Status.TransitionTo(next); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	update := models.TicketUpdateData{Status: &next, ReleasedAt: &now}
+	if next == models.TicketStatusRefunded {
+		update, err = uc.refund(ctx, existing)
 		if err != nil {
 			return err
 		}
-		existing = &existingFromDB
 	}
 
-	if existing.Status != models.TicketStatusReserved {
-		return models.ErrTicketNotReserved
+	reason := reasonCancelled
+	if next == models.TicketStatusRefunded {
+		reason = reasonRefunded
 	}
 
-	_, err = uc.repo.UpdateOne(
-		ctx,
-		models.TicketFilter{ID: &ticketID},
-		models.TicketUpdateData{
-			Status: models.TicketStatusCancelled.Ptr(),
-		},
-	)
+	_, err = uc.transition(ctx, existing, update, reason)
 	if err != nil {
+		// A concurrent cancel of the same version shared our refund at the
+		// provider and marked the ticket refunded itself.
+		if update.RefundID != nil && !errors.Is(err, models.ErrTicketRefunded) {
+			uc.log.Error("ticket refunded but not marked refunded", "ticket_id", ticketID, "refund_id", *update.RefundID, "error", err)
+		}
+
 		return err
 	}
 
@@ -153,7 +492,9 @@ func (uc *ticketUseCase) CancelTicket(ctx context.Context, ticketID string) erro
 
 	_ = uc.cache.InvalidateUserTickets(ctx, existing.UserID)
 
-	_ = uc.cache.CacheSeatAvailability(ctx, existing.SessionID, existing.SeatNumber, true)
+	_ = uc.cache.InvalidateSessionTickets(ctx, existing.SessionID)
+
+	_ = uc.cache.CacheSeatState(ctx, existing.SessionID, existing.SeatNumber, models.SeatStateFree)
 
 	return nil
 }
@@ -180,49 +521,148 @@ func (uc *ticketUseCase) GetTicket(ctx context.Context, id string) (*models.Tick
 	return &ticketFromDB, nil
 }
 
-func (uc *ticketUseCase) GetUserTickets(ctx context.Context, userID string) ([]*models.Ticket, error) {
-	tickets, err := uc.cache.GetUserTickets(ctx, userID)
+func (uc *ticketUseCase) GetUserTickets(ctx context.Context, userID string, page models.PageRequest) (*models.TicketPage, error) {
+	page, err := page.Normalize()
 	if err != nil {
-		uc.log.Warn("failed to get user tickets from cache", "user_id", userID, "error", err)
+		return nil, err
 	}
 
-	if tickets != nil {
-		return tickets, nil
+	// Only the first page in default order is cached; it is what users open most.
+	cacheable := page.IsFirstDefault()
+
+	if cacheable {
+		cached, err := uc.cache.GetUserTickets(ctx, userID)
+		if err != nil {
+			uc.log.Warn("failed to get user tickets from cache", "user_id", userID, "error", err)
+		}
+
+		if cached != nil {
+			return cached, nil
+		}
 	}
 
-	ticketsFromDB, err := uc.repo.Find(ctx, models.TicketFilter{UserID: &userID})
+	result, err := uc.findPage(ctx, models.TicketFilter{UserID: &userID}, page)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]*models.Ticket, len(ticketsFromDB))
-	for i := range ticketsFromDB {
-		result[i] = &ticketsFromDB[i]
+	if cacheable {
+		if err := uc.cache.CacheUserTickets(ctx, userID, result); err != nil {
+			uc.log.Warn("failed to cache user tickets after DB fetch", "user_id", userID, "error", err)
+		}
 	}
 
-	if err := uc.cache.CacheUserTickets(ctx, userID, result); err != nil {
-		uc.log.Warn("failed to cache user tickets after DB fetch", "user_id", userID, "error", err)
+	return result, nil
+}
+
+func (uc *ticketUseCase) GetAllTickets(ctx context.Context, page models.PageRequest) (*models.TicketPage, error) {
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return uc.findPage(ctx, models.TicketFilter{}, page)
 }
 
-func (uc *ticketUseCase) GetAllTickets(ctx context.Context) ([]*models.Ticket, error) {
-	ticketsFromDB, err := uc.repo.Find(ctx, models.TicketFilter{})
+func (uc *ticketUseCase) GetMovieTickets(ctx context.Context, movieID string, page models.PageRequest) (*models.TicketPage, error) {
+	page, err := page.Normalize()
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]*models.Ticket, len(ticketsFromDB))
-	for i := range ticketsFromDB {
-		result[i] = &ticketsFromDB[i]
+	return uc.findPage(ctx, models.TicketFilter{MovieID: &movieID}, page)
+}
+
+// GetSessionTickets lists the tickets booked for a session in seat order,
+// optionally only those in one of statuses. A session holds at most one
+// hall's worth of live tickets, so the whole list is cached per session
+// and filtered in memory.
+func (uc *ticketUseCase) GetSessionTickets(ctx context.Context, sessionID string, statuses []models.TicketStatus) ([]*models.Ticket, error) {
+	for _, status := range statuses {
+		if !status.IsValid() {
+			return nil, models.ErrInvalidTicketStatus
+		}
+	}
+
+	tickets, err := uc.cache.GetSessionTickets(ctx, sessionID)
+	if err != nil {
+		uc.log.Warn("failed to get session tickets from cache", "session_id", sessionID, "error", err)
+	}
+
+	if tickets == nil {
+		ticketsFromDB, _, err := uc.repo.Find(ctx, models.TicketFilter{SessionID: &sessionID}, models.PageRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		tickets = make([]*models.Ticket, len(ticketsFromDB))
+		for i := range ticketsFromDB {
+			tickets[i] = &ticketsFromDB[i]
+		}
+
+		if err := uc.sortBySeat(ctx, sessionID, tickets); err != nil {
+			return nil, err
+		}
+
+		if err := uc.cache.CacheSessionTickets(ctx, sessionID, tickets); err != nil {
+			uc.log.Warn("failed to cache session tickets after DB fetch", "session_id", sessionID, "error", err)
+		}
+	}
+
+	if len(statuses) == 0 {
+		return tickets, nil
+	}
+
+	result := make([]*models.Ticket, 0, len(tickets))
+	for _, ticket := range tickets {
+		if slices.Contains(statuses, ticket.Status) {
+			result = append(result, ticket)
+		}
 	}
 
 	return result, nil
 }
 
-func (uc *ticketUseCase) GetMovieTickets(ctx context.Context, movieID string) ([]*models.Ticket, error) {
-	ticketsFromDB, err := uc.repo.Find(ctx, models.TicketFilter{MovieID: &movieID})
+// SearchTickets lists the tickets matching filter for support staff.
+func (uc *ticketUseCase) SearchTickets(ctx context.Context, filter models.TicketFilter, page models.PageRequest) (*models.TicketPage, error) {
+	if err := filter.ValidateSearch(); err != nil {
+		return nil, err
+	}
+
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.findPage(ctx, filter, page)
+}
+
+// StreamTickets hands every ticket matching filter to send, in page's order
+// and starting after page's cursor. Page size is ignored; the stream ends
+// with the result set.
+func (uc *ticketUseCase) StreamTickets(
+	ctx context.Context,
+	filter models.TicketFilter,
+	page models.PageRequest,
+	send func(*models.Ticket) error,
+) error {
+	if err := filter.ValidateSearch(); err != nil {
+		return err
+	}
+
+	page, err := page.Normalize()
+	if err != nil {
+		return err
+	}
+	page.Size = 0
+
+	return uc.repo.Stream(ctx, filter, page, func(ticket models.Ticket) error {
+		return send(&ticket)
+	})
+}
+
+func (uc *ticketUseCase) findPage(ctx context.Context, filter models.TicketFilter, page models.PageRequest) (*models.TicketPage, error) {
+	ticketsFromDB, nextCursor, err := uc.repo.Find(ctx, filter, page)
 	if err != nil {
 		return nil, err
 	}
@@ -232,20 +672,322 @@ func (uc *ticketUseCase) GetMovieTickets(ctx context.Context, movieID string) ([
 		result[i] = &ticketsFromDB[i]
 	}
 
-	return result, nil
+	return &models.TicketPage{Tickets: result, NextCursor: nextCursor}, nil
 }
 
 func (uc *ticketUseCase) CheckSeatAvailability(ctx context.Context, sessionID, seatNumber string) (bool, error) {
-	if available, err := uc.cache.GetSeatAvailability(ctx, sessionID, seatNumber); err == nil && available != nil {
-		return *available, nil
+	hall, err := uc.sessionHall(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if hall != nil && !hall.HasSeat(seatNumber) {
+		return false, models.ErrInvalidSeatNumber
+	}
+
+	if state, err := uc.cache.GetSeatState(ctx, sessionID, seatNumber); err == nil && state != nil {
+		return *state == models.SeatStateFree, nil
 	}
 
-	available, err := uc.repo.IsSeatAvailable(ctx, sessionID, seatNumber)
+	seatMap, err := uc.sessionSeatStates(ctx, sessionID)
 	if err != nil {
 		return false, err
 	}
 
-	_ = uc.cache.CacheSeatAvailability(ctx, sessionID, seatNumber, available)
+	return seatMap.State(seatNumber) == models.SeatStateFree, nil
+}
+
+func (uc *ticketUseCase) GetSessionSeatMap(ctx context.Context, sessionID string) (*models.SeatMap, error) {
+	seatMap, err := uc.sessionSeatStates(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	hall, err := uc.sessionHall(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hall == nil {
+		return seatMap, nil
+	}
+
+	// Report free seats explicitly when the layout tells us which exist.
+	full := &models.SeatMap{
+		SessionID: sessionID,
+		Seats:     make(map[string]models.SeatState, hall.Capacity()),
+	}
+	for _, seat := range hall.SeatNumbers() {
+		full.Seats[seat] = seatMap.State(seat)
+	}
+
+	return full, nil
+}
+
+// sessionSeatStates returns the occupied seats of a session, preferring the cached seat map.
+func (uc *ticketUseCase) sessionSeatStates(ctx context.Context, sessionID string) (*models.SeatMap, error) {
+	seatMap, err := uc.cache.GetSeatMap(ctx, sessionID)
+	if err != nil {
+		uc.log.Warn("failed to get seat map from cache", "session_id", sessionID, "error", err)
+	}
+
+	if seatMap != nil {
+		return seatMap, nil
+	}
+
+	seats, err := uc.repo.FindSeatStates(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	seatMap = &models.SeatMap{
+		SessionID: sessionID,
+		Seats:     seats,
+	}
+
+	if err := uc.cache.CacheSeatMap(ctx, seatMap); err != nil {
+		uc.log.Warn("failed to cache seat map after DB fetch", "session_id", sessionID, "error", err)
+	}
+
+	return seatMap, nil
+}
+
+// sessionHall returns the layout linked to a session. A session without
+// one fails with models.ErrHallNotFound, unless unmapped sessions are
+// allowed by configuration; then it returns nil and seats go unvalidated.
+func (uc *ticketUseCase) sessionHall(ctx context.Context, sessionID string) (*models.Hall, error) {
+	hall, err := uc.halls.FindBySession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, models.ErrHallNotFound) && uc.allowUnmappedSessions {
+			uc.log.Debug("no hall layout linked to session", "session_id", sessionID)
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &hall, nil
+}
 
-	return available, nil
+// sortBySeat orders tickets by seat in the session's hall layout. Seats the
+// layout does not know, and sessions without one, follow in natural seat
+// number order.
+func (uc *ticketUseCase) sortBySeat(ctx context.Context, sessionID string, tickets []*models.Ticket) error {
+	var order map[string]int
+
+	hall, err := uc.halls.FindBySession(ctx, sessionID)
+	switch {
+	case err == nil:
+		order = hall.SeatOrder()
+	case !errors.Is(err, models.ErrHallNotFound):
+		return err
+	}
+
+	slices.SortStableFunc(tickets, func(a, b *models.Ticket) int {
+		posA, inA := order[a.SeatNumber]
+		posB, inB := order[b.SeatNumber]
+
+		switch {
+		case inA && inB:
+			return cmp.Compare(posA, posB)
+		case inA:
+			return -1
+		case inB:
+			return 1
+		default:
+			return models.CompareSeatNumbers(a.SeatNumber, b.SeatNumber)
+		}
+	})
+
+	return nil
+}
+
+// releaseSeatLock hands a seat lock back even when ctx is already done, so
+// a cancelled request does not leave the seat locked until the lock expires.
+func (uc *ticketUseCase) releaseSeatLock(ctx context.Context, sessionID, seatNumber, token string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), seatLockReleaseTimeout)
+	defer cancel()
+
+	if err := uc.locker.ReleaseSeatLock(ctx, sessionID, seatNumber, token); err != nil {
+		uc.log.Warn("failed to release seat lock", "session_id", sessionID, "seat_number", seatNumber, "error", err)
+	}
+}
+
+func (uc *ticketUseCase) checkCapacity(ctx context.Context, sessionID string, hall models.Hall, seats int) error {
+	seatMap, err := uc.sessionSeatStates(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if len(seatMap.Seats)+seats > hall.Capacity() {
+		return models.ErrSessionFull
+	}
+
+	return nil
+}
+
+func (uc *ticketUseCase) ExpireReservations(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	expired := 0
+	err := uc.eachTicket(ctx, models.TicketFilter{
+		Status:        models.TicketStatusReserved.Ptr(),
+		ExpiresBefore: &now,
+	}, func(ticket models.Ticket) error {
+		if err := ticket.Status.TransitionTo(models.TicketStatusExpired); err != nil {
+			return nil
+		}
+
+		// The version check keeps a payment that lands mid-sweep from being overwritten.
+		_, err := uc.applyTransition(
+			ctx,
+			ticket,
+			models.TicketUpdateData{Status: models.TicketStatusExpired.Ptr(), ReleasedAt: &now},
+			reasonExpired,
+		)
+		if err != nil {
+			if errors.Is(err, models.ErrTicketVersionConflict) || errors.Is(err, models.ErrTicketNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		expired++
+
+		_ = uc.cache.InvalidateTicket(ctx, ticket.ID)
+
+		_ = uc.cache.InvalidateUserTickets(ctx, ticket.UserID)
+
+		_ = uc.cache.InvalidateSessionTickets(ctx, ticket.SessionID)
+
+		if err := uc.cache.CacheSeatState(ctx, ticket.SessionID, ticket.SeatNumber, models.SeatStateFree); err != nil {
+			uc.log.Warn("failed to release seat in cache", "ticket_id", ticket.ID, "error", err)
+		}
+
+		return nil
+	})
+
+	return expired, err
+}
+
+// eachTicket hands the tickets matching filter to fn, loading them
+// sweepPageSize at a time so a backlog built up during downtime never has
+// to fit in memory at once. It stops at the first error from fn.
+func (uc *ticketUseCase) eachTicket(ctx context.Context, filter models.TicketFilter, fn func(models.Ticket) error) error {
+	page := models.PageRequest{Size: sweepPageSize}
+
+	for {
+		tickets, next, err := uc.repo.Find(ctx, filter, page)
+		if err != nil {
+			return err
+		}
+
+		for _, ticket := range tickets {
+			if err := fn(ticket); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+		page.Cursor = next
+	}
+}
+
+func (uc *ticketUseCase) GetTicketHistory(ctx context.Context, ticketID string) ([]*models.TicketEvent, error) {
+	if _, err := uc.GetTicket(ctx, ticketID); err != nil {
+		return nil, err
+	}
+
+	eventsFromDB, err := uc.events.FindByTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.TicketEvent, len(eventsFromDB))
+	for i := range eventsFromDB {
+		result[i] = &eventsFromDB[i]
+	}
+
+	return result, nil
+}
+
+// transition applies a status change like applyTransition. When another
+// writer got there first it reloads the ticket, re-validates the move
+// against the new state and tries again a bounded number of times.
+func (uc *ticketUseCase) transition(
+	ctx context.Context,
+	ticket models.Ticket,
+	update models.TicketUpdateData,
+	reason string,
+) (models.Ticket, error) {
+	for attempt := 1; ; attempt++ {
+		updated, err := uc.applyTransition(ctx, ticket, update, reason)
+		if !errors.Is(err, models.ErrTicketVersionConflict) || attempt == maxTransitionAttempts {
+			return updated, err
+		}
+
+		_ = uc.cache.InvalidateTicket(ctx, ticket.ID)
+
+		ticket, err = uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticket.ID})
+		if err != nil {
+			return models.Ticket{}, err
+		}
+
+		if err := checkUpdate(ticket, update); err != nil {
+			return models.Ticket{}, err
+		}
+	}
+}
+
+// applyTransition applies update, usually a move to update.Status, and
+// records the change in its history within one transaction, releasing the
+// ticket's promo redemption when it is cancelled, expired or refunded. The update only applies while the
+// stored version still matches ticket.Version, so a concurrent change makes
+// it fail with models.ErrTicketVersionConflict.
+func (uc *ticketUseCase) applyTransition(
+	ctx context.Context,
+	ticket models.Ticket,
+	update models.TicketUpdateData,
+	reason string,
+) (models.Ticket, error) {
+	var updated models.Ticket
+
+	err := uc.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = uc.repo.UpdateOne(
+			ctx,
+			models.TicketFilter{ID: &ticket.ID, Version: &ticket.Version},
+			update,
+		)
+		if err != nil {
+			return err
+		}
+
+		// A ticket that ends unused gives its promo redemption back.
+		if ticket.PromoCode != nil && releasesPromo(updated.Status) {
+			if err := uc.promos.Release(ctx, *ticket.PromoCode, ticket.ID); err != nil {
+				return err
+			}
+		}
+
+		return uc.events.InsertMany(ctx, []models.TicketEvent{
+			newTicketEvent(ctx, updated, ticket.Status, reason),
+		})
+	})
+
+	return updated, err
+}
+
+func newTicketEvent(ctx context.Context, ticket models.Ticket, oldStatus models.TicketStatus, reason string) models.TicketEvent {
+	return models.TicketEvent{
+		TicketID:  ticket.ID,
+		Actor:     models.ActorFromContext(ctx),
+		Reason:    reason,
+		OldStatus: oldStatus,
+		NewStatus: ticket.Status,
+		CreatedAt: time.Now(),
+	}
 }
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"ap2final_ticket_service/internal/adapter/cache"
+	"ap2final_ticket_service/internal/models"
+)
+
+func TestReserveTicketConcurrentSameSeat(t *testing.T) {
+	const (
+		sessionID = "665f1c2e8b3e4a0012345678"
+		movieID   = "665f1c2e8b3e4a0012345679"
+		seat      = "A1"
+		callers   = 64
+	)
+
+	tests := []struct {
+		name        string
+		locker      cache.SeatLocker
+		uniqueSeats bool
+	}{
+		{name: "lock and unique index", locker: cache.NewMemorySeatLocker(time.Minute), uniqueSeats: true},
+		{name: "lock only", locker: cache.NewMemorySeatLocker(time.Minute)},
+		{name: "unique index only", locker: grantingLocker{}, uniqueSeats: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTicketRepo{uniqueSeats: tt.uniqueSeats}
+			uc := newTestUseCase(repo, tt.locker)
+
+			start := make(chan struct{})
+			errs := make([]error, callers)
+
+			var wg sync.WaitGroup
+			for i := range callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+
+					userID := fmt.Sprintf("%024x", 1000+i)
+					_, errs[i] = uc.ReserveTicket(context.Background(), sessionID, movieID, userID, seat, nil, "")
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			reserved := 0
+			for _, err := range errs {
+				switch {
+				case err == nil:
+					reserved++
+				case errors.Is(err, models.ErrSeatAlreadyTaken), errors.Is(err, models.ErrSeatLocked):
+				default:
+					t.Errorf("ReserveTicket() unexpected error: %v", err)
+				}
+			}
+
+			if reserved != 1 {
+				t.Errorf("%d callers reserved the seat, want 1", reserved)
+			}
+
+			if held := repo.active(sessionID, seat); len(held) != 1 {
+				t.Errorf("seat is held by %d active tickets, want 1", len(held))
+			}
+		})
+	}
+}
+
+func TestReserveTicketAfterCancel(t *testing.T) {
+	const (
+		sessionID = "665f1c2e8b3e4a0012345678"
+		movieID   = "665f1c2e8b3e4a0012345679"
+		seat      = "A2"
+	)
+
+	repo := &memTicketRepo{uniqueSeats: true}
+	uc := newTestUseCase(repo, cache.NewMemorySeatLocker(time.Minute))
+	ctx := context.Background()
+
+	first, err := uc.ReserveTicket(ctx, sessionID, movieID, "665f1c2e8b3e4a00000000a1", seat, nil, "")
+	if err != nil {
+		t.Fatalf("ReserveTicket() error = %v", err)
+	}
+
+	if _, err := uc.ReserveTicket(ctx, sessionID, movieID, "665f1c2e8b3e4a00000000a2", seat, nil, ""); !errors.Is(err, models.ErrSeatAlreadyTaken) {
+		t.Fatalf("second ReserveTicket() error = %v, want %v", err, models.ErrSeatAlreadyTaken)
+	}
+
+	if err := uc.CancelTicket(ctx, first.ID); err != nil {
+		t.Fatalf("CancelTicket() error = %v", err)
+	}
+
+	// The seat lock of the first reservation was released, and a cancelled
+	// ticket no longer holds the seat.
+	if _, err := uc.ReserveTicket(ctx, sessionID, movieID, "665f1c2e8b3e4a00000000a2", seat, nil, ""); err != nil {
+		t.Fatalf("ReserveTicket() after cancel error = %v", err)
+	}
+}
+
+// ctxLocker refuses to release a lock once the caller's context is done,
+// as a network-backed locker would.
+type ctxLocker struct {
+	*cache.MemorySeatLocker
+}
+
+func (l ctxLocker) ReleaseSeatLock(ctx context.Context, sessionID, seatNumber, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.MemorySeatLocker.ReleaseSeatLock(ctx, sessionID, seatNumber, token)
+}
+
+func TestReleaseSeatLockOutlivesRequest(t *testing.T) {
+	locker := ctxLocker{cache.NewMemorySeatLocker(time.Minute)}
+	uc := newTestUseCase(&memTicketRepo{}, locker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	token, err := locker.AcquireSeatLock(ctx, "s1", "A1")
+	if err != nil {
+		t.Fatalf("AcquireSeatLock() error = %v", err)
+	}
+
+	// The request is gone by the time its deferred release runs.
+	cancel()
+	uc.releaseSeatLock(ctx, "s1", "A1", token)
+
+	if _, err := locker.AcquireSeatLock(context.Background(), "s1", "A1"); err != nil {
+		t.Fatalf("seat still locked after release: %v", err)
+	}
+}
+
+func TestExpireReservationsInPages(t *testing.T) {
+	const (
+		sessionID = "665f1c2e8b3e4a0012345678"
+		stale     = 2*sweepPageSize + 1
+	)
+
+	repo := &memTicketRepo{}
+	uc := newTestUseCase(repo, grantingLocker{})
+
+	past := time.Now().Add(-time.Minute)
+	for i := range stale {
+		repo.tickets = append(repo.tickets, models.Ticket{
+			ID:         fmt.Sprintf("%024x", i+1),
+			SessionID:  sessionID,
+			SeatNumber: fmt.Sprintf("S%d", i),
+			Status:     models.TicketStatusReserved,
+			ExpiresAt:  past,
+		})
+	}
+	repo.nextID = stale
+
+	// Still within its hold, so the sweep leaves it alone.
+	held, err := repo.InsertOne(context.Background(), &models.Ticket{
+		SessionID: sessionID,
+		Status:    models.TicketStatusReserved,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("InsertOne() error = %v", err)
+	}
+
+	expired, err := uc.ExpireReservations(context.Background())
+	if err != nil {
+		t.Fatalf("ExpireReservations() error = %v", err)
+	}
+
+	if expired != stale {
+		t.Errorf("ExpireReservations() = %d, want %d", expired, stale)
+	}
+	if repo.largestPage > sweepPageSize {
+		t.Errorf("sweep loaded %d tickets at once, want at most %d", repo.largestPage, sweepPageSize)
+	}
+
+	ticket, err := repo.FindOne(context.Background(), models.TicketFilter{ID: &held.ID})
+	if err != nil {
+		t.Fatalf("FindOne() error = %v", err)
+	}
+	if ticket.Status != models.TicketStatusReserved {
+		t.Errorf("ticket within its hold is %s, want %s", ticket.Status, models.TicketStatusReserved)
+	}
+}
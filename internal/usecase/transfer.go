@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+)
+
+// InitiateTransfer offers the ticket to toUserID on behalf of the
+// authenticated user in ctx, who must own it. A new offer replaces any
+// earlier one that is still pending.
+func (uc *ticketUseCase) InitiateTransfer(
+	ctx context.Context,
+	ticketID, toUserID string,
+) (*models.TicketTransfer, error) {
+	fromUserID, ok := models.UserFromContext(ctx)
+	if !ok {
+		return nil, models.ErrUnauthenticated
+	}
+
+	if toUserID == "" || toUserID == fromUserID {
+		return nil, models.ErrInvalidUserID
+	}
+
+	ticket, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.UserID != fromUserID {
+		return nil, models.ErrUserNotAllowed
+	}
+
+	if err := uc.checkTransferable(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var created models.TicketTransfer
+	err = uc.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.transfers.CancelPending(ctx, ticketID); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = uc.transfers.InsertOne(ctx, models.TicketTransfer{
+			TicketID:   ticketID,
+			FromUserID: fromUserID,
+			ToUserID:   toUserID,
+			Status:     models.TransferStatusPending,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// AcceptTransfer hands the ticket to the transfer's recipient, who must be
+// the authenticated user in ctx.
+func (uc *ticketUseCase) AcceptTransfer(ctx context.Context, transferID string) (*models.Ticket, error) {
+	userID, ok := models.UserFromContext(ctx)
+	if !ok {
+		return nil, models.ErrUnauthenticated
+	}
+
+	transfer, err := uc.transfers.FindByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.Status != models.TransferStatusPending {
+		return nil, models.ErrTransferNotPending
+	}
+
+	if transfer.ToUserID != userID {
+		return nil, models.ErrUserNotAllowed
+	}
+
+	ticket, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &transfer.TicketID})
+	if err != nil {
+		return nil, err
+	}
+
+	// The ticket changed hands since the offer was made.
+	if ticket.UserID != transfer.FromUserID {
+		if _, err := uc.transfers.Resolve(ctx, transfer.ID, models.TransferStatusCancelled); err != nil && !errors.Is(err, models.ErrTransferNotPending) {
+			uc.log.Warn("failed to cancel stale transfer", "transfer_id", transfer.ID, "error", err)
+		}
+
+		return nil, models.ErrTransferNotPending
+	}
+
+	if err := uc.checkTransferable(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("transferred from user %s to user %s", transfer.FromUserID, transfer.ToUserID)
+
+	var updatedTicket models.Ticket
+	err = uc.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := uc.transfers.Resolve(ctx, transfer.ID, models.TransferStatusAccepted); err != nil {
+			return err
+		}
+
+		var err error
+		updatedTicket, err = uc.repo.UpdateOne(
+			ctx,
+			models.TicketFilter{ID: &ticket.ID, Version: &ticket.Version},
+			models.TicketUpdateData{UserID: &transfer.ToUserID},
+		)
+		if err != nil {
+			return err
+		}
+
+		return uc.events.InsertMany(ctx, []models.TicketEvent{
+			newTicketEvent(ctx, updatedTicket, ticket.Status, reason),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.cache.CacheTicket(ctx, &updatedTicket); err != nil {
+		uc.log.Warn("failed to cache transferred ticket", "ticket_id", updatedTicket.ID, "error", err)
+	}
+
+	_ = uc.cache.InvalidateUserTickets(ctx, transfer.FromUserID)
+
+	_ = uc.cache.InvalidateUserTickets(ctx, transfer.ToUserID)
+
+	_ = uc.cache.InvalidateSessionTickets(ctx, updatedTicket.SessionID)
+
+	return &updatedTicket, nil
+}
+
+// checkTransferable allows transfers of paid tickets whose showtime has
+// not started. Sessions missing from the schedule fail with
+// models.ErrSessionNotFound.
+func (uc *ticketUseCase) checkTransferable(ctx context.Context, ticket models.Ticket) error {
+	if err := ticket.Status.EnsureActive(); err != nil {
+		return err
+	}
+
+	if ticket.Status != models.TicketStatusPaid {
+		return models.ErrTicketNotPaid
+	}
+
+	_, err := uc.upcomingSession(ctx, ticket.SessionID, time.Now())
+
+	return err
+}
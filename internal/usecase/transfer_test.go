@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ap2final_ticket_service/internal/adapter/cache"
+	"ap2final_ticket_service/internal/models"
+)
+
+func TestTransferTicket(t *testing.T) {
+	const (
+		sessionID = "665f1c2e8b3e4a0012345678"
+		owner     = "665f1c2e8b3e4a00000000a1"
+		recipient = "665f1c2e8b3e4a00000000a2"
+		stranger  = "665f1c2e8b3e4a00000000a3"
+	)
+
+	upcoming := scheduledSessions{sessionID: {ID: sessionID, StartsAt: time.Now().Add(time.Hour)}}
+	started := scheduledSessions{sessionID: {ID: sessionID, StartsAt: time.Now().Add(-time.Hour)}}
+
+	tests := []struct {
+		name       string
+		sessions   SessionRepository
+		sender     string
+		accepter   string
+		wantOffer  error
+		wantAccept error
+	}{
+		{name: "owner to recipient", sessions: upcoming, sender: owner, accepter: recipient},
+		{name: "anonymous sender", sessions: upcoming, wantOffer: models.ErrUnauthenticated},
+		{name: "sender does not own the ticket", sessions: upcoming, sender: stranger, wantOffer: models.ErrUserNotAllowed},
+		{name: "session not in schedule", sessions: noSessions{}, sender: owner, wantOffer: models.ErrSessionNotFound},
+		{name: "showtime started", sessions: started, sender: owner, wantOffer: models.ErrShowtimeStarted},
+		{name: "anonymous accepter", sessions: upcoming, sender: owner, wantAccept: models.ErrUnauthenticated},
+		{name: "accepted by someone else", sessions: upcoming, sender: owner, accepter: stranger, wantAccept: models.ErrUserNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memTicketRepo{}
+			uc := newTestUseCase(repo, cache.NewMemorySeatLocker(time.Minute))
+			uc.sessions = tt.sessions
+			uc.transfers = &memTransferRepo{}
+
+			ticket, err := repo.InsertOne(context.Background(), &models.Ticket{
+				SessionID:  sessionID,
+				UserID:     owner,
+				SeatNumber: "A1",
+				Price:      models.NewMoney(2500, "KZT"),
+				Status:     models.TicketStatusPaid,
+			})
+			if err != nil {
+				t.Fatalf("InsertOne() error = %v", err)
+			}
+
+			ctx := context.Background()
+			if tt.sender != "" {
+				ctx = models.WithActor(ctx, tt.sender)
+			}
+
+			transfer, err := uc.InitiateTransfer(ctx, ticket.ID, recipient)
+			if !errors.Is(err, tt.wantOffer) {
+				t.Fatalf("InitiateTransfer() error = %v, want %v", err, tt.wantOffer)
+			}
+			if err != nil {
+				return
+			}
+
+			if transfer.FromUserID != owner {
+				t.Errorf("transfer is from %q, want the owner %q", transfer.FromUserID, owner)
+			}
+
+			ctx = context.Background()
+			if tt.accepter != "" {
+				ctx = models.WithActor(ctx, tt.accepter)
+			}
+
+			accepted, err := uc.AcceptTransfer(ctx, transfer.ID)
+			if !errors.Is(err, tt.wantAccept) {
+				t.Fatalf("AcceptTransfer() error = %v, want %v", err, tt.wantAccept)
+			}
+			if err != nil {
+				return
+			}
+
+			if accepted.UserID != recipient {
+				t.Errorf("ticket belongs to %q, want %q", accepted.UserID, recipient)
+			}
+		})
+	}
+}
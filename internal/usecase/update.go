@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ap2final_ticket_service/internal/models"
+)
+
+// UpdateTicket applies an admin change. A status change is routed to the
+// flow that owns it: PAID confirms payment, CANCELLED and REFUNDED cancel
+// (refunding paid tickets) and USED checks the ticket in. Without a status,
+// only the price and payment method of an unpaid reservation can change,
+// and only an admin may correct the price.
+func (uc *ticketUseCase) UpdateTicket(
+	ctx context.Context,
+	ticketID string,
+	update models.TicketUpdateData,
+) (*models.Ticket, error) {
+	if update.SessionID != nil || update.SeatNumber != nil {
+		return nil, fmt.Errorf("%w: use ExchangeTicket to change the seat", models.ErrInvalidTicketData)
+	}
+
+	if update.UserID != nil {
+		return nil, fmt.Errorf("%w: use a transfer to change the owner", models.ErrInvalidTicketData)
+	}
+
+	if update.PurchaseTime != nil || update.PaymentID != nil || update.Discount != nil || update.RefundID != nil || update.RefundAmount != nil {
+		return nil, fmt.Errorf("%w: only status, price and payment method can be updated", models.ErrInvalidTicketData)
+	}
+
+	if update.Status != nil {
+		if update.Price != nil {
+			return nil, fmt.Errorf("%w: price cannot change together with status", models.ErrInvalidTicketData)
+		}
+
+		return uc.updateStatus(ctx, ticketID, *update.Status, update.PaymentMethod)
+	}
+
+	if update.Price == nil && update.PaymentMethod == nil {
+		return nil, fmt.Errorf("%w: nothing to update", models.ErrInvalidTicketData)
+	}
+
+	if update.Price != nil && !models.IsAdmin(ctx) {
+		return nil, fmt.Errorf("%w: only an admin can correct the price", models.ErrUserNotAllowed)
+	}
+
+	existing, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUpdate(existing, update); err != nil {
+		return nil, err
+	}
+
+	updatedTicket, err := uc.transition(ctx, existing, update, editReason(existing, update))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.cache.CacheTicket(ctx, &updatedTicket); err != nil {
+		uc.log.Warn("failed to cache updated ticket", "ticket_id", updatedTicket.ID, "error", err)
+	}
+
+	_ = uc.cache.InvalidateUserTickets(ctx, updatedTicket.UserID)
+
+	_ = uc.cache.InvalidateSessionTickets(ctx, updatedTicket.SessionID)
+
+	return &updatedTicket, nil
+}
+
+func (uc *ticketUseCase) updateStatus(
+	ctx context.Context,
+	ticketID string,
+	status models.TicketStatus,
+	paymentMethod *string,
+) (*models.Ticket, error) {
+	if !status.IsValid() {
+		return nil, models.ErrInvalidTicketStatus
+	}
+
+	switch status {
+	case models.TicketStatusPaid:
+		method := ""
+		if paymentMethod != nil {
+			method = *paymentMethod
+		}
+
+		return uc.ConfirmPayment(ctx, ticketID, method)
+	case models.TicketStatusCancelled, models.TicketStatusRefunded:
+		if err := uc.CancelTicket(ctx, ticketID); err != nil {
+			return nil, err
+		}
+
+		return uc.GetTicket(ctx, ticketID)
+	case models.TicketStatusUsed:
+		return uc.useTicket(ctx, ticketID)
+	default:
+		// RESERVED and EXPIRED are only reached through reservation and the reaper.
+		return nil, fmt.Errorf("%w: cannot set status %s directly", models.ErrInvalidTicketStatus, status)
+	}
+}
+
+func (uc *ticketUseCase) useTicket(ctx context.Context, ticketID string) (*models.Ticket, error) {
+	existing, err := uc.repo.FindOne(ctx, models.TicketFilter{ID: &ticketID})
+	if err != nil {
+		return nil, err
+	}
+
+	update := models.TicketUpdateData{Status: models.TicketStatusUsed.Ptr()}
+	if err := checkUpdate(existing, update); err != nil {
+		return nil, err
+	}
+
+	updatedTicket, err := uc.transition(ctx, existing, update, reasonUsed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.cache.CacheTicket(ctx, &updatedTicket); err != nil {
+		uc.log.Warn("failed to cache used ticket", "ticket_id", updatedTicket.ID, "error", err)
+	}
+
+	_ = uc.cache.InvalidateUserTickets(ctx, updatedTicket.UserID)
+
+	_ = uc.cache.InvalidateSessionTickets(ctx, updatedTicket.SessionID)
+
+	return &updatedTicket, nil
+}
+
+// checkUpdate validates update against the current state of ticket. Status
+// changes follow the transition table; edits need a live reservation.
+func checkUpdate(ticket models.Ticket, update models.TicketUpdateData) error {
+	if update.Status != nil {
+		return ticket.Status.TransitionTo(*update.Status)
+	}
+
+	switch {
+	case ticket.Status == models.TicketStatusPaid:
+		return models.ErrTicketAlreadyPaid
+	case ticket.Status != models.TicketStatusReserved:
+		return models.ErrTicketNotReserved
+	case ticket.IsExpired(time.Now()):
+		return models.ErrTicketExpired
+	}
+
+	if update.Price != nil {
+		if update.Price.IsNegative() {
+			return fmt.Errorf("%w: price cannot be negative", models.ErrInvalidTicketData)
+		}
+
+		if update.Price.Currency != ticket.Price.Currency {
+			return fmt.Errorf("%w: ticket is priced in %s", models.ErrCurrencyMismatch, ticket.Price.Currency)
+		}
+	}
+
+	if update.PaymentMethod != nil && *update.PaymentMethod == "" {
+		return models.ErrInvalidPaymentMethod
+	}
+
+	return nil
+}
+
+func editReason(ticket models.Ticket, update models.TicketUpdateData) string {
+	var changes []string
+
+	if update.Price != nil {
+		changes = append(changes, fmt.Sprintf("price corrected from %s to %s", ticket.Price, *update.Price))
+	}
+
+	if update.PaymentMethod != nil {
+		changes = append(changes, fmt.Sprintf("payment method changed to %s", *update.PaymentMethod))
+	}
+
+	return strings.Join(changes, "; ")
+}